@@ -6,22 +6,189 @@ import "time"
 /*                              Config variables                              */
 /* -------------------------------------------------------------------------- */
 var MAX_PER_PAGE = float64(50)
+var DEFAULT_PER_PAGE = uint(10)
 
 var MAX_POST_TITLE_CHAR = 100
 var MAX_POST_TEXT_CHAR = 5000
 var USER_POST_COOLDOWN = time.Second * 45
 
+// Limits of posts/list filters
+var MAX_FILTER_AUTHORS = 20
+var MAX_SEARCH_TEXT_CHAR = 100
+var POST_PUBLISHER_INTERVAL = time.Second * 30
+
+// Repeated views of a Post by the same User within the window are only counted once
+var POST_VIEW_DEDUP_WINDOW = time.Minute * 30
+var POST_VIEWS_FLUSH_INTERVAL = time.Second * 15
+var MAX_POST_ANALYTICS_DAYS = 90
+
+// Soft deleted Posts, Comments and Users are purged after this period
+var TRASH_RETENTION_PERIOD = time.Hour * 24 * 30
+var TRASH_PURGE_INTERVAL = time.Hour
+
+// Counters of Users and Posts are checked against their actual values in batches of ids
+var RECONCILE_INTERVAL = time.Hour * 6
+var RECONCILE_BATCH_SIZE = uint(500)
+
+// Number of latest Posts in RSS and Atom feeds
+var FEED_ITEMS_COUNT = uint(20)
+
 var MAX_COMMENT_TEXT_CHAR = 1000
 var USER_COMMENT_COOLDOWN = time.Second * 20
 
+// Top level Comments have depth 0, replies can be nested up to this depth
+var MAX_COMMENT_DEPTH = uint(5)
+
+// Users can change their username once per cooldown, their old username is held for them for the hold period
+var USERNAME_CHANGE_COOLDOWN = time.Hour * 24 * 30
+var USERNAME_HOLD_PERIOD = time.Hour * 24 * 90
+
 var MAX_USER_BIO_LENGTH = 100
+var MAX_USER_DISPLAY_NAME_LENGTH = 50
+var MAX_USER_PRONOUNS_LENGTH = 30
+var MAX_USER_FACULTY_LENGTH = 100
+var MAX_USER_YEAR = uint(6)
+var MAX_PROFILE_LINKS = 5
+var MAX_PROFILE_LINK_LABEL_LENGTH = 30
+var MAX_PROFILE_LINK_URL_LENGTH = 255
+
+// Avatars are resized to fit within AVATAR_SIZE x AVATAR_SIZE pixels
+var MAX_AVATAR_SIZE = int64(5 << 20) // 5 MB
+var AVATAR_SIZE = 256
+
+// Fenced code blocks in Posts (and their Comments) with these tags are syntax highlighted
+var CODE_HIGHLIGHT_TAGS = map[string]bool{"cs": true}
+
+var MAX_LOCK_REASON_CHAR = 200
+
+// Posts (and their Comments) with these tags can be made anonymous
+var ANONYMOUS_TAGS = map[string]bool{"life": true, "misc": true}
+
+// Posts with these tags are questions, their author (or a moderator) can accept a Comment as the answer
+var QA_TAGS = map[string]bool{"cs": true}
+
+var MAX_ATTACHMENT_SIZE = int64(10 << 20) // 10 MB
+var MAX_ATTACHMENTS_PER_POST = 10
+var MAX_ATTACHMENTS_PER_COMMENT = 4
+var MAX_IMAGE_DIMENSION = 8000
+var ATTACHMENT_THUMBNAIL_SIZE = 320
+var ATTACHMENT_URL_EXPIRY = time.Hour
+
+// Allowed content types and the extension they are stored with.
+// Content types are sniffed from the file contents, the uploaded file name and headers are ignored.
+var ALLOWED_ATTACHMENT_TYPES = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+	"text/plain":      ".txt",
+}
+
+var MIN_POLL_OPTIONS = 2
+var MAX_POLL_OPTIONS = 10
+var MAX_POLL_OPTION_CHAR = 100
+
+// Only the first few mentions in a Post or Comment are linked and notified
+var MAX_MENTIONS_PER_TEXT = 10
+
+// Users subscribed to a Post are notified of its new Comments
+var AUTO_SUBSCRIBE_AUTHORS = true
+var AUTO_SUBSCRIBE_COMMENTERS = true
+
+var MAX_MESSAGE_TEXT_CHAR = 2000
+var USER_MESSAGE_COOLDOWN = time.Second * 2
+
+// Group conversations have at most this many participants (including the User that started it)
+var MAX_CONVERSATION_PARTICIPANTS = 10
+var MAX_CONVERSATION_TITLE_CHAR = 100
+
+// Posts and Comments are hidden once they have this many pending reports
+var REPORTS_TO_HIDE = 5
+var MAX_REPORT_DETAILS_CHAR = 500
+var MAX_REPORT_NOTE_CHAR = 500
 
 /* -------------------------------------------------------------------------- */
 /*                                 USER ROLES                                 */
 /* -------------------------------------------------------------------------- */
 const (
-	USER_ROLE_ADMIN  = "admin"
-	USER_ROLE_MEMBER = "member"
+	USER_ROLE_ADMIN     = "admin"
+	USER_ROLE_MODERATOR = "moderator"
+	USER_ROLE_MEMBER    = "member"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                POST STATUSES                               */
+/* -------------------------------------------------------------------------- */
+const (
+	POST_STATUS_PUBLISHED = "published"
+	POST_STATUS_DRAFT     = "draft"
+	POST_STATUS_SCHEDULED = "scheduled"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                               REPORT TARGETS                               */
+/* -------------------------------------------------------------------------- */
+const (
+	REPORT_TARGET_POST    = "post"
+	REPORT_TARGET_COMMENT = "comment"
+	REPORT_TARGET_USER    = "user"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                               REPORT REASONS                               */
+/* -------------------------------------------------------------------------- */
+const (
+	REPORT_REASON_SPAM           = "spam"
+	REPORT_REASON_HARASSMENT     = "harassment"
+	REPORT_REASON_HATE           = "hate"
+	REPORT_REASON_NSFW           = "nsfw"
+	REPORT_REASON_MISINFORMATION = "misinformation"
+	REPORT_REASON_OTHER          = "other"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                               REPORT STATUSES                              */
+/* -------------------------------------------------------------------------- */
+const (
+	REPORT_STATUS_PENDING   = "pending"
+	REPORT_STATUS_RESOLVED  = "resolved"
+	REPORT_STATUS_DISMISSED = "dismissed"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                               REPORT OUTCOMES                              */
+/* -------------------------------------------------------------------------- */
+const (
+	// Reported Post or Comment is deleted
+	REPORT_OUTCOME_REMOVED = "removed"
+	// Reported Post or Comment stays hidden
+	REPORT_OUTCOME_HIDDEN = "hidden"
+	// Reported Post is locked against new comments
+	REPORT_OUTCOME_LOCKED = "locked"
+	// Author was warned, content is left as is
+	REPORT_OUTCOME_WARNED = "warned"
+	// Set on dismissed reports
+	REPORT_OUTCOME_NONE = "none"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                             NOTIFICATION TYPES                             */
+/* -------------------------------------------------------------------------- */
+const (
+	NOTIFICATION_MENTION = "mention"
+	NOTIFICATION_FOLLOW  = "follow"
+	NOTIFICATION_COMMENT = "comment"
+	NOTIFICATION_REPLY   = "reply"
+	NOTIFICATION_ANSWER  = "answer"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                               REACTION TYPES                               */
+/* -------------------------------------------------------------------------- */
+const (
+	REACTION_UPVOTE   = "upvote"
+	REACTION_DOWNVOTE = "downvote"
 )
 
 /* -------------------------------------------------------------------------- */
@@ -31,4 +198,5 @@ const (
 	SORT_BYRECENT = "commented_at DESC, id DESC"
 	SORT_BYNEW    = "created_at  DESC, id DESC"
 	SORT_BYHOT    = "comments_count DESC, commented_at DESC"
+	SORT_BYTOP    = "score DESC, created_at DESC, id DESC"
 )
@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mfjkri/OneNUS-Backend/config"
+	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                GetReconcileResult | route: /admin/reconcile                */
+/* -------------------------------------------------------------------------- */
+func GetReconcileResult(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Check User is admin
+	if user.Role != config.USER_ROLE_ADMIN {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You do not have valid permissions."})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, CreateReconcileResponse())
+}
+
+/* -------------------------------------------------------------------------- */
+/*                 RunReconcile | route: /admin/reconcile/run                 */
+/* -------------------------------------------------------------------------- */
+func RunReconcile(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Check User is admin
+	if user.Role != config.USER_ROLE_ADMIN {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You do not have valid permissions."})
+		return
+	}
+
+	// Start reconciliation now instead of waiting for the next scheduled run.
+	// It can take a while, its result is fetched from admin/reconcile once it is no longer running.
+	if StartReconcileCounters() == false {
+		c.JSON(http.StatusConflict, gin.H{"message": "Reconciliation is already running."})
+		return
+	}
+
+	fmt.Printf("%s has started reconciling users and posts counters.\n", user.Username)
+
+	c.JSON(http.StatusAccepted, CreateReconcileResponse())
+}
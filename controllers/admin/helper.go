@@ -0,0 +1,280 @@
+package admin
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mfjkri/OneNUS-Backend/config"
+	"github.com/mfjkri/OneNUS-Backend/database"
+	"github.com/mfjkri/OneNUS-Backend/models"
+	"gorm.io/gorm"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                           Counter reconciliation                           */
+/* -------------------------------------------------------------------------- */
+// Actual values of the counters, as correlated subqueries on users and posts
+const (
+	actualUserPostsCount    = "(SELECT COUNT(*) FROM posts WHERE posts.user_id = users.id AND posts.status = @published AND posts.deleted_at IS NULL)"
+	actualUserCommentsCount = "(SELECT COUNT(*) FROM comments WHERE comments.user_id = users.id AND comments.deleted_at IS NULL)"
+	actualPostCommentsCount = "(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id AND comments.deleted_at IS NULL)"
+	actualPostCommentedAt   = "(SELECT COALESCE(MAX(comments.created_at), @epoch) FROM comments WHERE comments.post_id = posts.id AND comments.deleted_at IS NULL)"
+	actualPostStarsCount    = "(SELECT COUNT(*) FROM stars WHERE stars.post_id = posts.id)"
+)
+
+type ReconcileResult struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	UsersChecked int64
+	PostsChecked int64
+
+	// Number of rows whose counter had drifted, by counter
+	UserPostsCountDrift    int64
+	UserCommentsCountDrift int64
+	PostCommentsCountDrift int64
+	PostCommentedAtDrift   int64
+	PostStarsCountDrift    int64
+
+	UsersFixed int64
+	PostsFixed int64
+
+	// First few drifted rows, for investigating the cause
+	SampleUserIDs []uint
+	SamplePostIDs []uint
+
+	Error string
+}
+
+var reconcileMutex sync.Mutex
+
+var lastResultMutex sync.RWMutex
+var lastResult *ReconcileResult
+
+// Number of sample IDs kept in a ReconcileResult
+const maxSampleIDs = 20
+
+func appendSampleIDs(sampleIDs []uint, ids []uint) []uint {
+	for _, id := range ids {
+		if len(sampleIDs) >= maxSampleIDs {
+			break
+		}
+		sampleIDs = append(sampleIDs, id)
+	}
+	return sampleIDs
+}
+
+// Recounts PostsCount and CommentsCount of the Users with ID in [startID, endID) and fixes the ones that drifted
+func reconcileUsers(result *ReconcileResult, startID uint, endID uint) error {
+	type userDrift struct {
+		ID                 uint
+		PostsCountDrift    bool
+		CommentsCountDrift bool
+	}
+
+	var drifts []userDrift
+	err := database.DB.Raw(`SELECT * FROM (
+		SELECT users.id,
+			users.posts_count <> `+actualUserPostsCount+` AS posts_count_drift,
+			users.comments_count <> `+actualUserCommentsCount+` AS comments_count_drift
+		FROM users WHERE users.id >= @start AND users.id < @end AND users.deleted_at IS NULL
+	) AS drifts WHERE posts_count_drift OR comments_count_drift`, map[string]interface{}{
+		"published": config.POST_STATUS_PUBLISHED,
+		"start":     startID,
+		"end":       endID,
+	}).Scan(&drifts).Error
+	if err != nil || len(drifts) == 0 {
+		return err
+	}
+
+	var ids []uint
+	for _, drift := range drifts {
+		ids = append(ids, drift.ID)
+		if drift.PostsCountDrift {
+			result.UserPostsCountDrift += 1
+		}
+		if drift.CommentsCountDrift {
+			result.UserCommentsCountDrift += 1
+		}
+	}
+	result.SampleUserIDs = appendSampleIDs(result.SampleUserIDs, ids)
+
+	// Counters are recounted by the UPDATE itself so that concurrent changes since the check are not lost
+	fix := database.DB.Model(&models.User{}).Where("id IN ?", ids).UpdateColumns(map[string]interface{}{
+		"posts_count":    gorm.Expr(actualUserPostsCount, map[string]interface{}{"published": config.POST_STATUS_PUBLISHED}),
+		"comments_count": gorm.Expr(actualUserCommentsCount),
+	})
+	result.UsersFixed += fix.RowsAffected
+	return fix.Error
+}
+
+// Recounts CommentsCount, CommentedAt and StarsCount of the Posts with ID in [startID, endID) and fixes the ones that drifted.
+// UpdatedAt of the Posts is left untouched, it should only reflect changes to post.Text
+func reconcilePosts(result *ReconcileResult, startID uint, endID uint) error {
+	type postDrift struct {
+		ID                 uint
+		CommentsCountDrift bool
+		CommentedAtDrift   bool
+		StarsCountDrift    bool
+	}
+
+	var drifts []postDrift
+	err := database.DB.Raw(`SELECT * FROM (
+		SELECT posts.id,
+			posts.comments_count <> `+actualPostCommentsCount+` AS comments_count_drift,
+			posts.commented_at <> `+actualPostCommentedAt+` AS commented_at_drift,
+			posts.stars_count <> `+actualPostStarsCount+` AS stars_count_drift
+		FROM posts WHERE posts.id >= @start AND posts.id < @end AND posts.deleted_at IS NULL
+	) AS drifts WHERE comments_count_drift OR commented_at_drift OR stars_count_drift`, map[string]interface{}{
+		"epoch": time.Unix(0, 0),
+		"start": startID,
+		"end":   endID,
+	}).Scan(&drifts).Error
+	if err != nil || len(drifts) == 0 {
+		return err
+	}
+
+	var ids []uint
+	for _, drift := range drifts {
+		ids = append(ids, drift.ID)
+		if drift.CommentsCountDrift {
+			result.PostCommentsCountDrift += 1
+		}
+		if drift.CommentedAtDrift {
+			result.PostCommentedAtDrift += 1
+		}
+		if drift.StarsCountDrift {
+			result.PostStarsCountDrift += 1
+		}
+	}
+	result.SamplePostIDs = appendSampleIDs(result.SamplePostIDs, ids)
+
+	// Counters are recounted by the UPDATE itself so that concurrent changes since the check are not lost
+	fix := database.DB.Model(&models.Post{}).Where("id IN ?", ids).UpdateColumns(map[string]interface{}{
+		"comments_count": gorm.Expr(actualPostCommentsCount),
+		"commented_at":   gorm.Expr(actualPostCommentedAt, map[string]interface{}{"epoch": time.Unix(0, 0)}),
+		"stars_count":    gorm.Expr(actualPostStarsCount),
+	})
+	result.PostsFixed += fix.RowsAffected
+	return fix.Error
+}
+
+// Runs reconcile on consecutive ID ranges of config.RECONCILE_BATCH_SIZE rows of model
+func reconcileInBatches(model interface{}, result *ReconcileResult, checked *int64, reconcile func(*ReconcileResult, uint, uint) error) error {
+	var maxID uint
+	database.DB.Model(model).Select("COALESCE(MAX(id), 0)").Scan(&maxID)
+	database.DB.Model(model).Count(checked)
+
+	for startID := uint(1); startID <= maxID; startID += config.RECONCILE_BATCH_SIZE {
+		if err := reconcile(result, startID, startID+config.RECONCILE_BATCH_SIZE); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Finds and fixes drifted PostsCount and CommentsCount of Users and CommentsCount, CommentedAt and StarsCount of Posts.
+// Returns false without doing anything if a reconciliation is already running.
+func ReconcileCounters() bool {
+	if !reconcileMutex.TryLock() {
+		return false
+	}
+	defer reconcileMutex.Unlock()
+
+	reconcileCounters()
+	return true
+}
+
+// Same as ReconcileCounters but runs in the background, returns once the reconciliation has started
+func StartReconcileCounters() bool {
+	if !reconcileMutex.TryLock() {
+		return false
+	}
+
+	go func() {
+		defer reconcileMutex.Unlock()
+		reconcileCounters()
+	}()
+	return true
+}
+
+// Must only be called while holding reconcileMutex
+func reconcileCounters() {
+	result := ReconcileResult{StartedAt: time.Now()}
+	err := reconcileInBatches(&models.User{}, &result, &result.UsersChecked, reconcileUsers)
+	if err == nil {
+		err = reconcileInBatches(&models.Post{}, &result, &result.PostsChecked, reconcilePosts)
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	result.FinishedAt = time.Now()
+
+	if result.UsersFixed > 0 || result.PostsFixed > 0 || err != nil {
+		fmt.Printf("Reconciled counters of %d users and %d posts.\n\tError: %s\n", result.UsersFixed, result.PostsFixed, result.Error)
+	}
+
+	lastResultMutex.Lock()
+	lastResult = &result
+	lastResultMutex.Unlock()
+}
+
+type ReconcileResponse struct {
+	// Ran is false until the first reconciliation has finished
+	Ran     bool `json:"ran" binding:"required"`
+	Running bool `json:"running" binding:"required"`
+
+	StartedAt  int64 `json:"startedAt"`
+	FinishedAt int64 `json:"finishedAt"`
+
+	UsersChecked int64 `json:"usersChecked"`
+	PostsChecked int64 `json:"postsChecked"`
+
+	UserPostsCountDrift    int64 `json:"userPostsCountDrift"`
+	UserCommentsCountDrift int64 `json:"userCommentsCountDrift"`
+	PostCommentsCountDrift int64 `json:"postCommentsCountDrift"`
+	PostCommentedAtDrift   int64 `json:"postCommentedAtDrift"`
+	PostStarsCountDrift    int64 `json:"postStarsCountDrift"`
+
+	UsersFixed int64 `json:"usersFixed"`
+	PostsFixed int64 `json:"postsFixed"`
+
+	SampleUserIDs []uint `json:"sampleUserIds"`
+	SamplePostIDs []uint `json:"samplePostIds"`
+
+	Error string `json:"error"`
+}
+
+// Convert the result of the last reconciliation into a JSON format
+func CreateReconcileResponse() ReconcileResponse {
+	running := !reconcileMutex.TryLock()
+	if !running {
+		reconcileMutex.Unlock()
+	}
+
+	lastResultMutex.RLock()
+	defer lastResultMutex.RUnlock()
+	if lastResult == nil {
+		return ReconcileResponse{Ran: false, Running: running}
+	}
+
+	return ReconcileResponse{
+		Ran:                    true,
+		Running:                running,
+		StartedAt:              lastResult.StartedAt.Unix(),
+		FinishedAt:             lastResult.FinishedAt.Unix(),
+		UsersChecked:           lastResult.UsersChecked,
+		PostsChecked:           lastResult.PostsChecked,
+		UserPostsCountDrift:    lastResult.UserPostsCountDrift,
+		UserCommentsCountDrift: lastResult.UserCommentsCountDrift,
+		PostCommentsCountDrift: lastResult.PostCommentsCountDrift,
+		PostCommentedAtDrift:   lastResult.PostCommentedAtDrift,
+		PostStarsCountDrift:    lastResult.PostStarsCountDrift,
+		UsersFixed:             lastResult.UsersFixed,
+		PostsFixed:             lastResult.PostsFixed,
+		SampleUserIDs:          lastResult.SampleUserIDs,
+		SamplePostIDs:          lastResult.SamplePostIDs,
+		Error:                  lastResult.Error,
+	}
+}
@@ -0,0 +1,8 @@
+package admin
+
+import "github.com/gin-gonic/gin"
+
+func RegisterRoutes(r *gin.Engine) {
+	r.GET("admin/reconcile", GetReconcileResult)
+	r.POST("admin/reconcile/run", RunReconcile)
+}
@@ -0,0 +1,281 @@
+package attachments
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mfjkri/OneNUS-Backend/config"
+	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/database"
+	"github.com/mfjkri/OneNUS-Backend/models"
+	"github.com/mfjkri/OneNUS-Backend/storage"
+)
+
+/* -------------------------------------------------------------------------- */
+/*           UploadPostAttachment | route: /attachments/post/:postId          */
+/* -------------------------------------------------------------------------- */
+type UploadPostAttachmentRequest struct {
+	PostID uint `uri:"postId" binding:"required"`
+}
+
+func UploadPostAttachment(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json UploadPostAttachmentRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Post from PostID
+	var post models.Post
+	database.DB.First(&post, json.PostID)
+	if post.ID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Post not found."})
+		return
+	}
+
+	// Check User is the author
+	if post.UserID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You do not have valid permissions."})
+		return
+	}
+
+	// Limit number of Attachments per Post
+	var attachmentsCount int64
+	database.DB.Table("attachments").Where("post_id = ?", post.ID).Count(&attachmentsCount)
+	if attachmentsCount >= int64(config.MAX_ATTACHMENTS_PER_POST) {
+		c.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("Posts can only have up to %d attachments.", config.MAX_ATTACHMENTS_PER_POST)})
+		return
+	}
+
+	// Validate and store uploaded file
+	attachment, uploaded := uploadAttachment(c, &user)
+	if uploaded == false {
+		return
+	}
+	attachment.PostID = &post.ID
+
+	// Failed to create entry
+	if new_entry := database.DB.Create(&attachment); new_entry.Error != nil {
+		deleteStoredFiles(&attachment)
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to upload attachment. Try again later."})
+		return
+	}
+
+	fmt.Printf("%s has uploaded an attachment.\n\tPost title: %s\n\tFile: %s (%s)\n", user.Username, post.Title, attachment.FileName, attachment.ContentType)
+
+	// Return new Attachment data
+	c.JSON(http.StatusAccepted, CreateAttachmentResponse(&attachment))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                    UploadCommentAttachment | route: ...                    */
+/* -------------------------------------------------------------------------- */
+// route: /attachments/comment/:commentId
+type UploadCommentAttachmentRequest struct {
+	CommentID uint `uri:"commentId" binding:"required"`
+}
+
+func UploadCommentAttachment(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json UploadCommentAttachmentRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Comment from CommentID
+	var comment models.Comment
+	database.DB.First(&comment, json.CommentID)
+	if comment.ID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Comment not found."})
+		return
+	}
+
+	// Check User is the author
+	if comment.UserID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You do not have valid permissions."})
+		return
+	}
+
+	// Check that parent Post is not locked
+	var post models.Post
+	database.DB.First(&post, comment.PostID)
+	if post.Locked {
+		c.JSON(http.StatusForbidden, gin.H{"message": "This post has been locked and is no longer accepting comments."})
+		return
+	}
+
+	// Limit number of Attachments per Comment
+	var attachmentsCount int64
+	database.DB.Table("attachments").Where("comment_id = ?", comment.ID).Count(&attachmentsCount)
+	if attachmentsCount >= int64(config.MAX_ATTACHMENTS_PER_COMMENT) {
+		c.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("Comments can only have up to %d attachments.", config.MAX_ATTACHMENTS_PER_COMMENT)})
+		return
+	}
+
+	// Validate and store uploaded file
+	attachment, uploaded := uploadAttachment(c, &user)
+	if uploaded == false {
+		return
+	}
+	attachment.CommentID = &comment.ID
+
+	// Failed to create entry
+	if new_entry := database.DB.Create(&attachment); new_entry.Error != nil {
+		deleteStoredFiles(&attachment)
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to upload attachment. Try again later."})
+		return
+	}
+
+	fmt.Printf("%s has uploaded an attachment.\n\tComment text: %s\n\tFile: %s (%s)\n", user.Username, comment.Text, attachment.FileName, attachment.ContentType)
+
+	// Return new Attachment data
+	c.JSON(http.StatusAccepted, CreateAttachmentResponse(&attachment))
+}
+
+/* -------------------------------------------------------------------------- */
+/*        GetAttachmentByID | route: /attachments/getbyid/:attachmentId       */
+/* -------------------------------------------------------------------------- */
+type GetAttachmentByIDRequest struct {
+	AttachmentID uint `uri:"attachmentId" binding:"required"`
+}
+
+func GetAttachmentByID(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetAttachmentByIDRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Attachment from AttachmentID
+	var attachment models.Attachment
+	database.DB.First(&attachment, json.AttachmentID)
+	if attachment.ID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Attachment not found."})
+		return
+	}
+
+	// Attachments of deleted Posts and Comments are kept until purged but not served.
+	// Attachments are only served to Users that can see their Post or Comment.
+	if !parentVisible(&attachment, &user) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Attachment not found."})
+		return
+	}
+
+	// Return fetched Attachment (with freshly signed URLs)
+	c.JSON(http.StatusAccepted, CreateAttachmentResponse(&attachment))
+}
+
+/* -------------------------------------------------------------------------- */
+/*         DeleteAttachment | route: /attachments/delete/:attachmentId        */
+/* -------------------------------------------------------------------------- */
+type DeleteAttachmentRequest struct {
+	AttachmentID uint `uri:"attachmentId" binding:"required"`
+}
+
+func DeleteAttachment(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json DeleteAttachmentRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Attachment from AttachmentID
+	var attachment models.Attachment
+	database.DB.First(&attachment, json.AttachmentID)
+	if attachment.ID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Attachment not found."})
+		return
+	}
+
+	// Check User is the uploader or is admin
+	if (attachment.UserID != user.ID) && (user.Role != config.USER_ROLE_ADMIN) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You do not have valid permissions."})
+		return
+	}
+
+	// Delete Attachment and its stored files
+	database.DB.Delete(&attachment)
+	deleteStoredFiles(&attachment)
+
+	fmt.Printf("%s has deleted an attachment.\n\tFile: %s\n", user.Username, attachment.FileName)
+
+	// Return deleted Attachment data
+	c.JSON(http.StatusAccepted, CreateAttachmentResponse(&attachment))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                      ServeFile | route: /storage/*key                      */
+/* -------------------------------------------------------------------------- */
+type ServeFileRequest struct {
+	Expires   string `form:"expires" binding:"required"`
+	Signature string `form:"signature" binding:"required"`
+}
+
+// Serves files saved by storage.LocalStorage. Other backends serve their own signed URLs.
+func ServeFile(c *gin.Context) {
+	localStorage, isLocal := storage.Store.(*storage.LocalStorage)
+	if isLocal == false {
+		c.JSON(http.StatusNotFound, gin.H{"message": "File not found."})
+		return
+	}
+
+	// Parse RequestBody
+	var json ServeFileRequest
+	if err := c.ShouldBindQuery(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Check that the URL was signed by us and has not expired
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if !localStorage.VerifySignature(key, json.Expires, json.Signature) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Link is invalid or has expired."})
+		return
+	}
+
+	filePath, err := localStorage.Path(key)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "File not found."})
+		return
+	}
+
+	// Never let browsers guess the type of user uploaded files
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Type", mime.TypeByExtension(path.Ext(key)))
+	if !strings.HasPrefix(mime.TypeByExtension(path.Ext(key)), "image/") {
+		c.Header("Content-Disposition", "attachment")
+	}
+	c.File(filePath)
+}
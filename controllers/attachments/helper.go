@@ -0,0 +1,204 @@
+package attachments
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/mfjkri/OneNUS-Backend/config"
+	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/database"
+	"github.com/mfjkri/OneNUS-Backend/models"
+	"github.com/mfjkri/OneNUS-Backend/storage"
+	"github.com/mfjkri/OneNUS-Backend/utils"
+	"gorm.io/gorm"
+)
+
+type AttachmentResponse struct {
+	ID           uint   `json:"id" binding:"required"`
+	FileName     string `json:"fileName" binding:"required"`
+	ContentType  string `json:"contentType" binding:"required"`
+	Size         int64  `json:"size" binding:"required"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	URL          string `json:"url" binding:"required"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+	UserID       uint   `json:"userId" binding:"required"`
+	CreatedAt    int64  `json:"createdAt" binding:"required"`
+}
+
+// Convert an Attachment Model into a JSON format with signed URLs
+func CreateAttachmentResponse(attachment *models.Attachment) AttachmentResponse {
+	url, _ := storage.Store.SignedURL(attachment.Key, config.ATTACHMENT_URL_EXPIRY)
+
+	var thumbnailURL string
+	if attachment.ThumbnailKey != "" {
+		thumbnailURL, _ = storage.Store.SignedURL(attachment.ThumbnailKey, config.ATTACHMENT_URL_EXPIRY)
+	}
+
+	return AttachmentResponse{
+		ID:           attachment.ID,
+		FileName:     attachment.FileName,
+		ContentType:  attachment.ContentType,
+		Size:         attachment.Size,
+		Width:        attachment.Width,
+		Height:       attachment.Height,
+		URL:          url,
+		ThumbnailURL: thumbnailURL,
+		UserID:       attachment.UserID,
+		CreatedAt:    attachment.CreatedAt.Unix(),
+	}
+}
+
+// Bundles and convert multiple Attachment models into a JSON format
+func CreateAttachmentsResponse(attachments []models.Attachment) []AttachmentResponse {
+	attachmentsResponse := []AttachmentResponse{}
+	for _, attachment := range attachments {
+		attachmentsResponse = append(attachmentsResponse, CreateAttachmentResponse(&attachment))
+	}
+	return attachmentsResponse
+}
+
+// Reads the "file" field of a multipart upload, validates it and saves it (and its thumbnail) to storage.
+// The returned Attachment is not yet saved to the database.
+func uploadAttachment(c *gin.Context, user *models.User) (models.Attachment, bool) {
+	var attachment models.Attachment
+
+	// Hard limit on the request body so oversized uploads are never fully read
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.MAX_ATTACHMENT_SIZE+(1<<20))
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "No file uploaded or file is too large."})
+		return attachment, false
+	}
+
+	if fileHeader.Size > config.MAX_ATTACHMENT_SIZE {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": fmt.Sprintf("File is too large (max %d MB).", config.MAX_ATTACHMENT_SIZE>>20)})
+		return attachment, false
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Unable to read uploaded file."})
+		return attachment, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, config.MAX_ATTACHMENT_SIZE))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Unable to read uploaded file."})
+		return attachment, false
+	}
+
+	// Sniff content type from the file contents
+	contentType := strings.Split(http.DetectContentType(data), ";")[0]
+	extension, allowed := config.ALLOWED_ATTACHMENT_TYPES[contentType]
+	if allowed == false {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"message": "File type is not allowed."})
+		return attachment, false
+	}
+
+	name := uuid.NewString()
+	attachment = models.Attachment{
+		FileName:    sanitizeFileName(fileHeader.Filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Key:         "attachments/" + name + extension,
+		UserID:      user.ID,
+	}
+
+	// Check image dimensions and generate a thumbnail
+	var thumbnail []byte
+	var thumbnailType string
+	if strings.HasPrefix(contentType, "image/") {
+		imageConfig, _, err := utils.DecodeImageConfig(data)
+		if err != nil {
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"message": "Image could not be read."})
+			return attachment, false
+		}
+
+		if imageConfig.Width > config.MAX_IMAGE_DIMENSION || imageConfig.Height > config.MAX_IMAGE_DIMENSION {
+			c.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("Image is too large (max %dx%d pixels).", config.MAX_IMAGE_DIMENSION, config.MAX_IMAGE_DIMENSION)})
+			return attachment, false
+		}
+		attachment.Width = imageConfig.Width
+		attachment.Height = imageConfig.Height
+
+		thumbnail, thumbnailType, err = utils.ResizeImage(data, config.ATTACHMENT_THUMBNAIL_SIZE)
+		if err != nil {
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"message": "Image could not be read."})
+			return attachment, false
+		}
+		attachment.ThumbnailKey = "attachments/" + name + "_thumb" + config.ALLOWED_ATTACHMENT_TYPES[thumbnailType]
+	}
+
+	// Save files to storage
+	if err := storage.Store.Put(attachment.Key, bytes.NewReader(data), attachment.Size, contentType); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to upload attachment. Try again later."})
+		return attachment, false
+	}
+
+	if thumbnail != nil {
+		if err := storage.Store.Put(attachment.ThumbnailKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), thumbnailType); err != nil {
+			deleteStoredFiles(&attachment)
+			c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to upload attachment. Try again later."})
+			return attachment, false
+		}
+	}
+
+	return attachment, true
+}
+
+// Keeps only the base name of the uploaded file and strips characters we never want to echo back
+func sanitizeFileName(fileName string) string {
+	fileName = utils.NormalizeText(filepath.Base(strings.ReplaceAll(fileName, "\\", "/")))
+	if fileName == "" || fileName == "." || utils.ContainsIllegalCharacters(fileName, false) || utils.CharacterCount(fileName) > 255 {
+		return "file"
+	}
+	return fileName
+}
+
+// Checks that the Post or Comment of an Attachment has not been (soft) deleted and is visible to user.
+// Attachments without either are only visible to their uploader.
+func parentVisible(attachment *models.Attachment, user *models.User) bool {
+	var post models.Post
+	if attachment.PostID != nil {
+		database.DB.First(&post, *attachment.PostID)
+	} else if attachment.CommentID != nil {
+		var comment models.Comment
+		database.DB.First(&comment, *attachment.CommentID)
+		if comment.ID == 0 || !auth.CanSeeComment(user, &comment) {
+			return false
+		}
+		database.DB.First(&post, comment.PostID)
+	} else {
+		return attachment.UserID == user.ID
+	}
+	return post.ID != 0 && auth.CanSeePost(user, &post)
+}
+
+// Removes the files of an Attachment from storage
+func deleteStoredFiles(attachment *models.Attachment) {
+	storage.Store.Delete(attachment.Key)
+	if attachment.ThumbnailKey != "" {
+		storage.Store.Delete(attachment.ThumbnailKey)
+	}
+}
+
+// Deletes all Attachments (and their files) matched by dbContext.
+// Used before deleting the Posts, Comments or Users they belong to.
+func DeleteAttachmentsFromContext(dbContext *gorm.DB) {
+	var attachments []models.Attachment
+	dbContext.Find(&attachments)
+
+	for _, attachment := range attachments {
+		database.DB.Delete(&attachment)
+		deleteStoredFiles(&attachment)
+	}
+}
@@ -0,0 +1,15 @@
+package attachments
+
+import "github.com/gin-gonic/gin"
+
+func RegisterRoutes(r *gin.Engine) {
+	r.POST("attachments/post/:postId", UploadPostAttachment)
+	r.POST("attachments/comment/:commentId", UploadCommentAttachment)
+	r.GET("attachments/getbyid/:attachmentId", GetAttachmentByID)
+	r.DELETE("attachments/delete/:attachmentId", DeleteAttachment)
+}
+
+// Files are protected by their signed URL instead of a JWT token
+func RegisterPublicRoutes(r *gin.Engine) {
+	r.GET("storage/*key", ServeFile)
+}
@@ -44,6 +44,12 @@ func RegisterUser(c *gin.Context) {
 
 	username_lowered := strings.ToLower(json.Username)
 
+	// Recently changed usernames are held for their previous owner
+	if IsUsernameHeld(username_lowered, 0) {
+		c.JSON(http.StatusConflict, gin.H{"message": "User already exists."})
+		return
+	}
+
 	user := models.User{
 		Username: username_lowered,
 		Password: hash,
@@ -53,6 +59,7 @@ func RegisterUser(c *gin.Context) {
 
 		LastPostAt:    time.Unix(0, 0),
 		LastCommentAt: time.Unix(0, 0),
+		LastMessageAt: time.Unix(0, 0),
 	}
 	new_entry := database.DB.Create(&user)
 
@@ -63,7 +70,7 @@ func RegisterUser(c *gin.Context) {
 	}
 
 	// Generate JWT Token
-	jwt, err := utils.GenerateJWT(username_lowered)
+	jwt, err := utils.GenerateJWT(user.ID)
 	if err != nil {
 		c.JSON(http.StatusExpectationFailed, gin.H{"message": "Failed to create access token."})
 		return
@@ -107,7 +114,7 @@ func LoginUser(c *gin.Context) {
 		return
 	}
 
-	jwt, err := utils.GenerateJWT(username_lowered)
+	jwt, err := utils.GenerateJWT(user.ID)
 	if err != nil {
 		c.JSON(http.StatusExpectationFailed, gin.H{"message": "Failed to create access token."})
 		return
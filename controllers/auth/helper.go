@@ -2,11 +2,15 @@ package auth
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mfjkri/OneNUS-Backend/config"
 	"github.com/mfjkri/OneNUS-Backend/database"
 	"github.com/mfjkri/OneNUS-Backend/models"
 	"github.com/mfjkri/OneNUS-Backend/utils"
+	"gorm.io/gorm"
 )
 
 func FindUserFromID(c *gin.Context, userID uint) (models.User, bool) {
@@ -20,6 +24,84 @@ func FindUserFromID(c *gin.Context, userID uint) (models.User, bool) {
 	}
 }
 
+// Admins are implicitly moderators
+func IsModerator(user *models.User) bool {
+	return user.Role == config.USER_ROLE_ADMIN || user.Role == config.USER_ROLE_MODERATOR
+}
+
+// Real authors of anonymous content are only revealed to themselves and moderators
+func CanSeeAnonymousAuthor(viewer *models.User, authorID uint) bool {
+	return viewer.ID == authorID || IsModerator(viewer)
+}
+
+// Content hidden by reports is only visible to its author and moderators
+func CanSeeHiddenContent(viewer *models.User, authorID uint) bool {
+	return viewer.ID == authorID || IsModerator(viewer)
+}
+
+// Users that userID has blocked or that have blocked userID, as a subquery of user IDs
+func BlockedUsersQuery(userID uint) *gorm.DB {
+	return database.DB.Raw("SELECT user_id FROM blocks WHERE blocker_id = ? UNION SELECT blocker_id FROM blocks WHERE user_id = ?", userID, userID)
+}
+
+// Checks whether either User has blocked the other
+func IsBlocked(userID uint, otherUserID uint) bool {
+	var blocksCount int64
+	database.DB.Model(&models.Block{}).Where("(blocker_id = ? AND user_id = ?) OR (blocker_id = ? AND user_id = ?)", userID, otherUserID, otherUserID, userID).Count(&blocksCount)
+	return blocksCount > 0
+}
+
+func IsMuted(muterID uint, userID uint) bool {
+	var mutesCount int64
+	database.DB.Model(&models.Mute{}).Where("muter_id = ? AND user_id = ?", muterID, userID).Count(&mutesCount)
+	return mutesCount > 0
+}
+
+// Drafts and scheduled Posts are only visible to their author, Posts hidden by reports to their author and moderators.
+// Posts of blocked Users are not visible (anonymous Posts are, so that blocking does not reveal their author).
+func CanSeePost(viewer *models.User, post *models.Post) bool {
+	if post.Status != config.POST_STATUS_PUBLISHED {
+		return post.UserID == viewer.ID
+	}
+	if post.Hidden && !CanSeeHiddenContent(viewer, post.UserID) {
+		return false
+	}
+	return post.Anonymous || !IsBlocked(viewer.ID, post.UserID)
+}
+
+// Same as CanSeePost for a Comment, without checking its Post
+func CanSeeComment(viewer *models.User, comment *models.Comment) bool {
+	if comment.Hidden && !CanSeeHiddenContent(viewer, comment.UserID) {
+		return false
+	}
+	return comment.Anonymous || !IsBlocked(viewer.ID, comment.UserID)
+}
+
+// Leaves the Posts or Comments of blocked (either way) and muted Users out of the listings of user.
+// Anonymous content is kept, otherwise blocking or muting could be used to find out who wrote it.
+// So is content without a UserID (placeholders of purged Users), NOT IN never matches NULL.
+func FilterBlockedContent(dbContext *gorm.DB, user *models.User) *gorm.DB {
+	hiddenUsers := database.DB.Raw("SELECT user_id FROM blocks WHERE blocker_id = ? UNION SELECT blocker_id FROM blocks WHERE user_id = ? UNION SELECT user_id FROM mutes WHERE muter_id = ?", user.ID, user.ID, user.ID)
+	return dbContext.Where("anonymous = ? OR user_id IS NULL OR user_id NOT IN (?)", true, hiddenUsers)
+}
+
+// Time the User got their current username
+func usernameSince(user *models.User) time.Time {
+	var lastChange models.UsernameChange
+	database.DB.Where("user_id = ?", user.ID).Order("created_at DESC, id DESC").First(&lastChange)
+	if lastChange.ID == 0 {
+		return user.CreatedAt
+	}
+	return lastChange.CreatedAt
+}
+
+// Usernames that other Users changed away from recently are held for them (see config.USERNAME_HOLD_PERIOD)
+func IsUsernameHeld(username string, userID uint) bool {
+	var heldCount int64
+	database.DB.Model(&models.UsernameChange{}).Where("old_username = ? AND user_id <> ? AND created_at > ?", username, userID, time.Now().Add(-config.USERNAME_HOLD_PERIOD)).Count(&heldCount)
+	return heldCount > 0
+}
+
 // Verify RequestUser using their JWT token
 func VerifyAuth(c *gin.Context) (user models.User, found bool) {
 	found = false
@@ -31,16 +113,26 @@ func VerifyAuth(c *gin.Context) (user models.User, found bool) {
 		return
 	}
 
-	// Decode JWT token to username
-	username, err := utils.DecodeJWT(jwt_token)
+	// Decode JWT token to its subject
+	subject, issuedAt, err := utils.DecodeJWT(jwt_token)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Unable to decoded authorization token."})
 		return
 	}
 
-	// Search for User from username
+	// Search for User from subject
 	var target_user models.User
-	database.DB.Table("users").Where("username = ?", username).First(&target_user)
+	if userID, err := strconv.ParseUint(subject, 10, 64); err == nil {
+		database.DB.First(&target_user, userID)
+	} else {
+		// Legacy tokens have the username as subject (usernames are letters only).
+		// They are only accepted if the User already had that username when the token was issued,
+		// otherwise the token of a renamed User would log in whoever took their old username.
+		database.DB.Table("users").Where("username = ?", subject).First(&target_user)
+		if target_user.ID != 0 && usernameSince(&target_user).Truncate(time.Second).After(issuedAt) {
+			target_user = models.User{}
+		}
+	}
 	if target_user.ID == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Unauthorized."})
 		return
@@ -52,18 +144,31 @@ func VerifyAuth(c *gin.Context) (user models.User, found bool) {
 	return
 }
 
+// Number of Messages sent by others in the Conversations of user that user has not read yet.
+// Messages of blocked Users are not counted, they are not listed either.
+func CountUnreadMessages(user *models.User) int64 {
+	var unreadCount int64
+	database.DB.Model(&models.Message{}).
+		Joins("JOIN participants ON participants.conversation_id = messages.conversation_id AND participants.user_id = ?", user.ID).
+		Where("messages.id > participants.last_read_message_id AND messages.user_id <> ? AND messages.user_id NOT IN (?)", user.ID, BlockedUsersQuery(user.ID)).
+		Count(&unreadCount)
+	return unreadCount
+}
+
 // Convert a User Model into a JSON format
 type AuthResponse struct {
-	ID       uint   `json:"id" binding:"required"`
-	Username string `json:"username" binding:"required"`
-	Role     string `json:"role" binding:"required"`
+	ID                  uint   `json:"id" binding:"required"`
+	Username            string `json:"username" binding:"required"`
+	Role                string `json:"role" binding:"required"`
+	UnreadMessagesCount int64  `json:"unreadMessagesCount" binding:"required"`
 }
 
 func CreateAuthResponse(user *models.User) AuthResponse {
 	return AuthResponse{
-		ID:       user.ID,
-		Username: user.Username,
-		Role:     user.Role,
+		ID:                  user.ID,
+		Username:            user.Username,
+		Role:                user.Role,
+		UnreadMessagesCount: CountUnreadMessages(user),
 	}
 }
 
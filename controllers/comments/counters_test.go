@@ -0,0 +1,282 @@
+package comments_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mfjkri/OneNUS-Backend/config"
+	"github.com/mfjkri/OneNUS-Backend/controllers/comments"
+	"github.com/mfjkri/OneNUS-Backend/controllers/posts"
+	"github.com/mfjkri/OneNUS-Backend/database"
+	"github.com/mfjkri/OneNUS-Backend/models"
+	"github.com/mfjkri/OneNUS-Backend/utils"
+)
+
+// These tests need a MySQL database they are free to write to, e.g.
+// TEST_DB="user:password@tcp(localhost:3306)/onenus_test?charset=utf8mb4&parseTime=True&loc=Local" go test ./controllers/comments/
+func TestMain(m *testing.M) {
+	if os.Getenv("TEST_DB") != "" {
+		os.Setenv("DB", os.Getenv("TEST_DB"))
+		if os.Getenv("JWT_SECRET") == "" {
+			os.Setenv("JWT_SECRET", "test")
+		}
+		database.Connect()
+		database.Migrate()
+
+		// Commenters comment again right away
+		config.USER_COMMENT_COOLDOWN = 0
+		gin.SetMode(gin.TestMode)
+	}
+	os.Exit(m.Run())
+}
+
+type testUser struct {
+	models.User
+	token string
+}
+
+func createTestUsers(t *testing.T, count int) []testUser {
+	// Usernames are letters only
+	suffix := make([]byte, 8)
+	for i := range suffix {
+		suffix[i] = byte('a' + rand.Intn(26))
+	}
+
+	var users []testUser
+	for i := 0; i < count; i++ {
+		user := models.User{
+			Username:      fmt.Sprintf("counters%s%c", suffix, 'a'+i),
+			Role:          config.USER_ROLE_MEMBER,
+			LastPostAt:    time.Unix(0, 0),
+			LastCommentAt: time.Unix(0, 0),
+			LastMessageAt: time.Unix(0, 0),
+		}
+		if err := database.DB.Create(&user).Error; err != nil {
+			t.Fatalf("Unable to create user: %s", err)
+		}
+
+		token, err := utils.GenerateJWT(user.ID)
+		if err != nil {
+			t.Fatalf("Unable to generate JWT: %s", err)
+		}
+		users = append(users, testUser{User: user, token: token})
+	}
+
+	// Posts, Comments and Stars of the Users are removed by CascadeDelete
+	t.Cleanup(func() {
+		for _, user := range users {
+			database.DB.Unscoped().Delete(&user.User)
+		}
+	})
+	return users
+}
+
+func newTestRouter() *gin.Engine {
+	router := gin.New()
+	posts.RegisterRoutes(router)
+	comments.RegisterRoutes(router)
+	return router
+}
+
+// Sends a request as user and returns the response code and the decoded response
+func sendRequest(router *gin.Engine, user *testUser, method string, path string, body interface{}) (int, map[string]interface{}) {
+	var requestBody bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&requestBody).Encode(body)
+	}
+
+	request := httptest.NewRequest(method, path, &requestBody)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("authorization", user.token)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	var response map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	return recorder.Code, response
+}
+
+// Sends a request as user and returns the decoded response, failing the test unless it is accepted
+func send(t *testing.T, router *gin.Engine, user *testUser, method string, path string, body interface{}) map[string]interface{} {
+	code, response := sendRequest(router, user, method, path, body)
+	if code != http.StatusAccepted {
+		t.Errorf("%s %s by %s: %d %v", method, path, user.Username, code, response)
+	}
+	return response
+}
+
+func responseID(response map[string]interface{}) uint {
+	id, _ := response["id"].(float64)
+	return uint(id)
+}
+
+func TestCountersUnderConcurrency(t *testing.T) {
+	if os.Getenv("TEST_DB") == "" {
+		t.Skip("TEST_DB is not set")
+	}
+
+	router := newTestRouter()
+	users := createTestUsers(t, 9)
+	author, commenters := &users[0], users[1:]
+
+	post := models.Post{
+		Title:       "Counters",
+		Tag:         "misc",
+		Text:        "Counters",
+		TextHTML:    "<p>Counters</p>",
+		Status:      config.POST_STATUS_PUBLISHED,
+		Author:      author.Username,
+		UserID:      author.ID,
+		CommentedAt: time.Unix(0, 0),
+	}
+	if err := database.DB.Create(&post).Error; err != nil {
+		t.Fatalf("Unable to create post: %s", err)
+	}
+
+	root := responseID(send(t, router, author, "POST", "/comments/create", gin.H{"postId": post.ID, "text": "Root"}))
+	if root == 0 {
+		t.FailNow()
+	}
+
+	// Every commenter creates, deletes and restores Comments, replies to the root Comment and stars the Post at the same time
+	const rounds = 3
+	var wg sync.WaitGroup
+	for i := range commenters {
+		wg.Add(1)
+		go func(commenter *testUser) {
+			defer wg.Done()
+			for round := 0; round < rounds; round++ {
+				reply := responseID(send(t, router, commenter, "POST", "/comments/create", gin.H{"postId": post.ID, "parentId": root, "text": "Reply"}))
+				send(t, router, commenter, "POST", "/posts/star", gin.H{"postId": post.ID})
+
+				comment := responseID(send(t, router, commenter, "POST", "/comments/create", gin.H{"postId": post.ID, "text": "Comment"}))
+				send(t, router, commenter, "DELETE", fmt.Sprintf("/comments/delete/%d", comment), nil)
+				send(t, router, commenter, "POST", "/comments/restore", gin.H{"commentId": comment})
+
+				// Every other reply stays deleted as a placeholder
+				if round%2 == 0 {
+					send(t, router, commenter, "DELETE", fmt.Sprintf("/comments/delete/%d", reply), nil)
+				}
+				if round < rounds-1 {
+					send(t, router, commenter, "DELETE", fmt.Sprintf("/posts/unstar/%d", post.ID), nil)
+				}
+			}
+		}(&commenters[i])
+	}
+	wg.Wait()
+
+	// Counters must match the rows they count
+	database.DB.First(&post, post.ID)
+
+	var commentsCount int64
+	database.DB.Model(&models.Comment{}).Where("post_id = ?", post.ID).Count(&commentsCount)
+	if int64(post.CommentsCount) != commentsCount {
+		t.Errorf("post.CommentsCount is %d, expected %d", post.CommentsCount, commentsCount)
+	}
+
+	// CommentedAt is the creation time of the last Comment that is not deleted
+	var lastComment models.Comment
+	database.DB.Where("post_id = ?", post.ID).Order("created_at DESC").Limit(1).Find(&lastComment)
+	if !post.CommentedAt.Equal(lastComment.CreatedAt) {
+		t.Errorf("post.CommentedAt is %s, expected %s", post.CommentedAt, lastComment.CreatedAt)
+	}
+
+	var starsCount int64
+	database.DB.Model(&models.Star{}).Where("post_id = ?", post.ID).Count(&starsCount)
+	if int64(post.StarsCount) != starsCount || starsCount != int64(len(commenters)) {
+		t.Errorf("post.StarsCount is %d, expected %d (%d stars)", post.StarsCount, len(commenters), starsCount)
+	}
+
+	// RepliesCount includes deleted replies, they are only removed once purged
+	var rootComment models.Comment
+	database.DB.First(&rootComment, root)
+	var repliesCount int64
+	database.DB.Unscoped().Model(&models.Comment{}).Where("parent_id = ?", root).Count(&repliesCount)
+	if int64(rootComment.RepliesCount) != repliesCount {
+		t.Errorf("RepliesCount of the root comment is %d, expected %d", rootComment.RepliesCount, repliesCount)
+	}
+
+	for _, user := range users {
+		var dbUser models.User
+		database.DB.First(&dbUser, user.ID)
+
+		var userCommentsCount int64
+		database.DB.Model(&models.Comment{}).Where("user_id = ?", user.ID).Count(&userCommentsCount)
+		if int64(dbUser.CommentsCount) != userCommentsCount {
+			t.Errorf("CommentsCount of %s is %d, expected %d", user.Username, dbUser.CommentsCount, userCommentsCount)
+		}
+	}
+}
+
+func TestPostsCountUnderConcurrency(t *testing.T) {
+	if os.Getenv("TEST_DB") == "" {
+		t.Skip("TEST_DB is not set")
+	}
+
+	router := newTestRouter()
+	author := &createTestUsers(t, 1)[0]
+
+	post := models.Post{
+		Title:       "Posts count",
+		Tag:         "misc",
+		Text:        "Posts count",
+		TextHTML:    "<p>Posts count</p>",
+		Status:      config.POST_STATUS_PUBLISHED,
+		Author:      author.Username,
+		UserID:      author.ID,
+		CommentedAt: time.Unix(0, 0),
+	}
+	if err := database.DB.Create(&post).Error; err != nil {
+		t.Fatalf("Unable to create post: %s", err)
+	}
+	database.DB.Model(&author.User).UpdateColumn("posts_count", 1)
+
+	// Author deletes (or restores) the Post several times at once, only one of them changes PostsCount
+	const attempts = 5
+	steps := []struct {
+		method        string
+		path          string
+		body          interface{}
+		expectedCount uint
+	}{
+		{"DELETE", fmt.Sprintf("/posts/delete/%d", post.ID), nil, 0},
+		{"POST", "/posts/restore", gin.H{"postId": post.ID}, 1},
+	}
+	for _, step := range steps {
+		var wg sync.WaitGroup
+		var acceptedCount int64
+		var mutex sync.Mutex
+		for i := 0; i < attempts; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if code, _ := sendRequest(router, author, step.method, step.path, step.body); code == http.StatusAccepted {
+					mutex.Lock()
+					acceptedCount++
+					mutex.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if acceptedCount == 0 {
+			t.Errorf("%s %s was never accepted", step.method, step.path)
+		}
+
+		var dbUser models.User
+		database.DB.First(&dbUser, author.ID)
+		if dbUser.PostsCount != step.expectedCount {
+			t.Errorf("PostsCount after %s %s is %d, expected %d", step.method, step.path, dbUser.PostsCount, step.expectedCount)
+		}
+	}
+}
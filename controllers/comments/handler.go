@@ -3,14 +3,15 @@ package comments
 import (
 	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mfjkri/OneNUS-Backend/config"
 	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/controllers/notifications"
 	"github.com/mfjkri/OneNUS-Backend/database"
 	"github.com/mfjkri/OneNUS-Backend/models"
 	"github.com/mfjkri/OneNUS-Backend/utils"
+	"gorm.io/gorm"
 )
 
 /* -------------------------------------------------------------------------- */
@@ -27,7 +28,7 @@ type GetCommentsRequest struct {
 
 func GetComments(c *gin.Context) {
 	// Check that RequestUser is authenticated
-	_, found := auth.VerifyAuth(c)
+	user, found := auth.VerifyAuth(c)
 	if found == false {
 		return
 	}
@@ -40,19 +41,215 @@ func GetComments(c *gin.Context) {
 	}
 
 	// Find Post from PostID
-	var post models.Post
-	database.DB.First(&post, json.PostID)
-	if post.ID == 0 {
-		c.JSON(http.StatusForbidden, gin.H{"message": "Post not found."})
+	post, found := findCommentsPost(c, &user, json.PostID)
+	if found == false {
 		return
 	}
 
-	// Get all comments from Post
-	dbContext := database.DB.Table("comments").Where("post_id = ?", json.PostID)
-	comments, totalCommentsCount := GetCommentsFromContext(dbContext, json.PerPage, json.PageNumber, json.SortOption, json.SortOrder)
+	// Get all comments from Post (deleted Comments are included as placeholders)
+	comments, totalCommentsCount := getCommentsPage(&post, &user, json.PerPage, json.PageNumber, json.SortOption, json.SortOrder)
+	deletedCommentsCount := countDeletedComments(postCommentsContext(&post, &user, nil))
 
 	// Return fetched comments
-	c.JSON(http.StatusAccepted, CreateCommentsResponse(&comments, totalCommentsCount))
+	c.JSON(http.StatusAccepted, CreateCommentsResponse(&comments, totalCommentsCount, deletedCommentsCount, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                         GetCommentTree | route: ...                        */
+/* -------------------------------------------------------------------------- */
+// route: /comments/tree/:postId/:perPage/:pageNumber/:sortOption/:sortOrder
+// Pages through the top level Comments of a Post, each with all of its nested replies.
+func GetCommentTree(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetCommentsRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Post from PostID
+	post, found := findCommentsPost(c, &user, json.PostID)
+	if found == false {
+		return
+	}
+
+	// Get top level comments from Post and their replies
+	comments, totalCommentsCount := GetCommentsFromContext(topLevelCommentsContext(&post, &user), json.PerPage, json.PageNumber, json.SortOption, json.SortOrder)
+	deletedCommentsCount := countDeletedComments(topLevelCommentsContext(&post, &user))
+	tree := loadCommentTree(comments, &user)
+
+	commentsResponse := []CommentTreeResponse{}
+	for _, comment := range comments {
+		commentsResponse = append(commentsResponse, CreateCommentTreeResponse(&comment, tree, &user))
+	}
+
+	// Return fetched comment tree
+	c.JSON(http.StatusAccepted, GetCommentTreeResponse{
+		Comments:             commentsResponse,
+		CommentsCount:        totalCommentsCount,
+		DeletedCommentsCount: deletedCommentsCount,
+	})
+}
+
+/* -------------------------------------------------------------------------- */
+/*                       GetFlatCommentTree | route: ...                      */
+/* -------------------------------------------------------------------------- */
+// route: /comments/flat/:postId/:perPage/:pageNumber/:sortOption/:sortOrder
+// Same as GetCommentTree but flattened depth first, CommentsCount is the count of top level Comments.
+func GetFlatCommentTree(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetCommentsRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Post from PostID
+	post, found := findCommentsPost(c, &user, json.PostID)
+	if found == false {
+		return
+	}
+
+	// Get top level comments from Post and their replies
+	comments, totalCommentsCount := GetCommentsFromContext(topLevelCommentsContext(&post, &user), json.PerPage, json.PageNumber, json.SortOption, json.SortOrder)
+	deletedCommentsCount := countDeletedComments(topLevelCommentsContext(&post, &user))
+	tree := loadCommentTree(comments, &user)
+
+	commentsResponse := []CommentResponse{}
+	for _, comment := range comments {
+		commentsResponse = flattenCommentTree(commentsResponse, &comment, tree, &user)
+	}
+
+	// Return fetched comments with their depth
+	c.JSON(http.StatusAccepted, GetCommentsResponse{
+		Comments:             commentsResponse,
+		CommentsCount:        totalCommentsCount,
+		DeletedCommentsCount: deletedCommentsCount,
+	})
+}
+
+/* -------------------------------------------------------------------------- */
+/*            GetCommentThread | route: /comments/thread/:commentId           */
+/* -------------------------------------------------------------------------- */
+type GetCommentThreadRequest struct {
+	CommentID uint `uri:"commentId" binding:"required"`
+}
+
+func GetCommentThread(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetCommentThreadRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Comment from CommentID (deleted Comments are shown as placeholders)
+	comment, _, found := findListedComment(c, &user, json.CommentID)
+	if found == false {
+		return
+	}
+
+	// Return Comment with all of its nested replies
+	tree := loadCommentTree([]models.Comment{comment}, &user)
+	c.JSON(http.StatusAccepted, CreateCommentTreeResponse(&comment, tree, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*            GetCommentByID | route: /comments/getbyid/:commentId            */
+/* -------------------------------------------------------------------------- */
+type GetCommentByIDRequest struct {
+	CommentID uint `uri:"commentId" binding:"required"`
+}
+
+func GetCommentByID(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetCommentByIDRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Comment from CommentID (deleted Comments are shown as placeholders)
+	comment, _, found := findListedComment(c, &user, json.CommentID)
+	if found == false {
+		return
+	}
+
+	// Return fetched Comment
+	c.JSON(http.StatusAccepted, CreateCommentResponse(&comment, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                       GetCommentsAround | route: ...                       */
+/* -------------------------------------------------------------------------- */
+// route: /comments/around/:commentId/:perPage/:sortOption/:sortOrder
+// Fetches the page of GetComments (with the same PerPage and sort) that contains a Comment, for deep links.
+type GetCommentsAroundRequest struct {
+	CommentID  uint   `uri:"commentId" binding:"required"`
+	PerPage    uint   `uri:"perPage" binding:"required"`
+	SortOption string `uri:"sortOption"`
+	SortOrder  string `uri:"sortOrder"`
+}
+
+func GetCommentsAround(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetCommentsAroundRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Comment from CommentID (deleted Comments are shown as placeholders)
+	comment, post, found := findListedComment(c, &user, json.CommentID)
+	if found == false {
+		return
+	}
+
+	// Find the page that contains the Comment and fetch it
+	position, pageNumber := locateComment(&comment, &post, &user, json.PerPage, json.SortOption, json.SortOrder)
+	comments, totalCommentsCount := getCommentsPage(&post, &user, json.PerPage, pageNumber, json.SortOption, json.SortOrder)
+	deletedCommentsCount := countDeletedComments(postCommentsContext(&post, &user, nil))
+	commentsResponse := CreateCommentsResponse(&comments, totalCommentsCount, deletedCommentsCount, &user)
+
+	// Return fetched page with the position of the Comment
+	c.JSON(http.StatusAccepted, GetCommentsAroundResponse{
+		Comments:             commentsResponse.Comments,
+		CommentsCount:        commentsResponse.CommentsCount,
+		DeletedCommentsCount: commentsResponse.DeletedCommentsCount,
+		PageNumber:           pageNumber,
+		Position:             position,
+		RootID:               findThreadRoot(&comment).ID,
+	})
 }
 
 /* -------------------------------------------------------------------------- */
@@ -61,6 +258,12 @@ func GetComments(c *gin.Context) {
 type CreateCommentRequest struct {
 	PostID uint   `json:"postId" binding:"required"`
 	Text   string `json:"text" binding:"required"`
+
+	// Optional: the Comment being replied to (must belong to the same Post)
+	ParentID uint `json:"parentId"`
+
+	// Optional: hides the author behind a pseudonym (only for config.ANONYMOUS_TAGS)
+	Anonymous bool `json:"anonymous"`
 }
 
 func CreateComment(c *gin.Context) {
@@ -80,11 +283,45 @@ func CreateComment(c *gin.Context) {
 	// Find Post from PostID
 	var post models.Post
 	database.DB.First(&post, json.PostID)
-	if post.ID == 0 {
+	if post.ID == 0 || post.Status != config.POST_STATUS_PUBLISHED || (post.Hidden && !auth.CanSeeHiddenContent(&user, post.UserID)) {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Post not found."})
 		return
 	}
 
+	// Blocked Users cannot comment on each other's Posts
+	if !post.Anonymous && auth.IsBlocked(user.ID, post.UserID) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Post not found."})
+		return
+	}
+
+	// Check that Post is not locked
+	if post.Locked {
+		c.JSON(http.StatusForbidden, gin.H{"message": lockedPostMessage(&post)})
+		return
+	}
+
+	// Check that the Post Tag allows anonymous Comments
+	if json.Anonymous && !config.ANONYMOUS_TAGS[post.Tag] {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Anonymous commenting is not allowed for this post."})
+		return
+	}
+
+	// Find the Comment being replied to (if any)
+	var parent models.Comment
+	if json.ParentID != 0 {
+		database.DB.First(&parent, json.ParentID)
+		// Blocked Users cannot reply to each other
+		if parent.ID == 0 || parent.PostID != post.ID || (parent.Hidden && !auth.CanSeeHiddenContent(&user, parent.UserID)) || (!parent.Anonymous && auth.IsBlocked(user.ID, parent.UserID)) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Comment to reply to not found."})
+			return
+		}
+
+		if parent.Depth+1 > config.MAX_COMMENT_DEPTH {
+			c.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("Replies cannot be nested more than %d levels deep.", config.MAX_COMMENT_DEPTH)})
+			return
+		}
+	}
+
 	// Prevent frequent CreatePosts by User
 	timeNow, canCreateComment := utils.CheckTimeIsAfter(user.LastCommentAt, config.USER_COMMENT_COOLDOWN)
 	if canCreateComment == false {
@@ -93,48 +330,84 @@ func CreateComment(c *gin.Context) {
 		return
 	}
 
-	// Check that Title and Text does not contain illegal characters
-	if !(utils.ContainsValidCharactersOnly(json.Text)) {
-		c.JSON(http.StatusForbidden, gin.H{"message": "Comment contains illegal characters."})
+	// Check that Text is valid
+	text, err := utils.ValidateText("Comment", json.Text, config.MAX_COMMENT_TEXT_CHAR)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
 		return
 	}
 
-	// Try to create new Comment
+	// Try to create new Comment.
+	// CreatedAt is the same as CommentedAt of the Post so that the Post does not look drifted to admin reconciliation.
 	comment := models.Comment{
-		Text:   utils.TrimString(strings.TrimSpace(json.Text), config.MAX_COMMENT_TEXT_CHAR),
-		Author: user.Username,
-		User:   user,
-		Post:   post,
+		BaseModel: models.BaseModel{CreatedAt: timeNow},
+		Text:      text,
+		Author:    user.Username,
+		User:      user,
+		Anonymous: json.Anonymous,
+		Post:      post,
+	}
+	if parent.ID != 0 {
+		comment.ParentID = &parent.ID
+		comment.Depth = parent.Depth + 1
 	}
-	new_entry := database.DB.Create(&comment)
+	mentions := renderCommentText(&comment, &post, &user)
+
+	// Create Comment and update the counters of its author, Post and parent Comment together
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
+
+		// Update CommentsCount and LastCommentAt for User
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).UpdateColumns(map[string]interface{}{
+			"comments_count":  gorm.Expr("comments_count + ?", 1),
+			"last_comment_at": timeNow,
+		}).Error; err != nil {
+			return err
+		}
+
+		// Update CommentsCount and CommentedAt for Post (Comments created concurrently may commit out of order).
+		// UpdatedAt is left untouched, it should only reflect changes to post.Text
+		if err := tx.Model(&models.Post{}).Where("id = ?", post.ID).UpdateColumns(map[string]interface{}{
+			"comments_count": gorm.Expr("comments_count + ?", 1),
+			"commented_at":   gorm.Expr("GREATEST(commented_at, ?)", timeNow),
+		}).Error; err != nil {
+			return err
+		}
+
+		// Update RepliesCount of the parent Comment
+		if parent.ID != 0 {
+			return tx.Model(&models.Comment{}).Where("id = ?", parent.ID).UpdateColumn("replies_count", gorm.Expr("replies_count + ?", 1)).Error
+		}
+		return nil
+	})
 
 	// Failed to create entry
-	if new_entry.Error != nil {
+	if err != nil {
 		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to create comment. Try again later."})
 		return
 	}
 
-	// Successfully created a new Post
-
-	// Update CommentsCount and LastCommentAt for User
-	user.CommentsCount += 1
-	user.LastCommentAt = timeNow
-	database.DB.Save(&user)
+	// Successfully created a new Comment
+	notifiedUserIDs := saveCommentMentions(&comment, &user, mentions)
 
-	// Update CommentsCount and CommentedAt for Post
-	postUpdatedAt := post.UpdatedAt
-	post.CommentedAt = timeNow
-	post.CommentsCount += 1
-	database.DB.Save(&post)
+	// Notify the author of the parent Comment
+	if parent.ID != 0 {
+		notifications.Notify(parent.UserID, &user, config.NOTIFICATION_REPLY, post.ID, &comment.ID, comment.Anonymous)
+		notifiedUserIDs = append(notifiedUserIDs, parent.UserID)
+	}
 
-	// By default post.UpdatedAt will get updated by these changes but we don't want that.
-	// UpdatedAt should only reflect changes to post.Text
-	database.DB.Model(&post).Update("updated_at", postUpdatedAt)
+	// Notify subscribers of the Post (mentioned Users and the parent author have already been notified)
+	notifications.NotifySubscribers(&user, post.ID, comment.ID, comment.Anonymous, notifiedUserIDs)
+	if config.AUTO_SUBSCRIBE_COMMENTERS {
+		notifications.AutoSubscribe(user.ID, post.ID)
+	}
 
 	fmt.Printf("%s has created a comment.\n\tPost title: %s\n\tComment text: %s\n", user.Username, post.Title, comment.Text)
 
 	// Return new Comment data
-	c.JSON(http.StatusAccepted, CreateCommentResponse(&comment))
+	c.JSON(http.StatusAccepted, CreateCommentResponse(&comment, &user))
 }
 
 /* -------------------------------------------------------------------------- */
@@ -161,12 +434,20 @@ func UpdateCommentText(c *gin.Context) {
 
 	// Find Comment from CommentID
 	var comment models.Comment
-	database.DB.First(&comment, json.CommentID)
+	database.DB.Preload("Attachments").First(&comment, json.CommentID)
 	if comment.ID == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Comment not found."})
 		return
 	}
 
+	// Check that parent Post is not locked
+	var post models.Post
+	database.DB.First(&post, comment.PostID)
+	if post.Locked {
+		c.JSON(http.StatusForbidden, gin.H{"message": lockedPostMessage(&post)})
+		return
+	}
+
 	// Prevent frequent UpdateCommentText by User
 	timeNow, canUpdateComment := utils.CheckTimeIsAfter(user.LastCommentAt, config.USER_COMMENT_COOLDOWN)
 	if canUpdateComment == false {
@@ -181,16 +462,26 @@ func UpdateCommentText(c *gin.Context) {
 		return
 	}
 
-	// Replace Comment text and update User LastCommentAt
-	comment.Text = utils.TrimString(strings.TrimSpace(json.Text), config.MAX_COMMENT_TEXT_CHAR)
+	// Check that Text is valid
+	text, err := utils.ValidateText("Comment", json.Text, config.MAX_COMMENT_TEXT_CHAR)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Replace Comment text and update User LastCommentAt.
+	// Only the changed columns are written so that concurrent counter updates are not overwritten.
+	comment.Text = text
+	mentions := renderCommentText(&comment, &post, &user)
 	user.LastCommentAt = timeNow
-	database.DB.Save(&comment)
-	database.DB.Save(&user)
+	database.DB.Model(&comment).Updates(map[string]interface{}{"text": comment.Text, "text_html": comment.TextHTML})
+	database.DB.Model(&user).UpdateColumn("last_comment_at", user.LastCommentAt)
+	saveCommentMentions(&comment, &user, mentions)
 
 	fmt.Printf("%s has updated a comment.\n\tNew text: %s\n", user.Username, comment.Text)
 
 	// Return updated Comment data
-	c.JSON(http.StatusAccepted, CreateCommentResponse(&comment))
+	c.JSON(http.StatusAccepted, CreateCommentResponse(&comment, &user))
 }
 
 /* -------------------------------------------------------------------------- */
@@ -228,11 +519,278 @@ func DeleteComment(c *gin.Context) {
 		return
 	}
 
-	// Delete Comment
-	database.DB.Delete(&comment)
+	// Soft delete Comment, it is shown as a placeholder until it is purged
+	RemoveComment(&comment, &user)
 
 	fmt.Printf("%s has deleted a comment.\n\tComment text: %s\n", user.Username, comment.Text)
 
 	// Return deleted Comment data
-	c.JSON(http.StatusAccepted, CreateCommentResponse(&comment))
+	c.JSON(http.StatusAccepted, CreateCommentResponse(&comment, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                  RestoreComment | route: comments/restore                  */
+/* -------------------------------------------------------------------------- */
+type RestoreCommentRequest struct {
+	CommentID uint `json:"commentId" binding:"required"`
+}
+
+func RestoreComment(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json RestoreCommentRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find deleted Comment from CommentID
+	comment, found := findDeletedComment(c, &user, json.CommentID)
+	if found == false {
+		return
+	}
+
+	// Comments can only be restored while their Post still exists
+	var post models.Post
+	database.DB.First(&post, comment.PostID)
+	if post.ID == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"message": "The post of this comment has been deleted."})
+		return
+	}
+
+	// Comments of deleted Users are restored together with the User
+	var author models.User
+	database.DB.First(&author, comment.UserID)
+	if author.ID == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"message": "The author of this comment has been deleted."})
+		return
+	}
+
+	// Restore Comment
+	restoreComment(&comment)
+
+	fmt.Printf("%s has restored a comment.\n\tComment text: %s\n", user.Username, comment.Text)
+
+	// Return restored Comment data
+	c.JSON(http.StatusAccepted, CreateCommentResponse(&comment, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                   ReactToComment | route: comments/react                   */
+/* -------------------------------------------------------------------------- */
+type ReactToCommentRequest struct {
+	CommentID uint   `json:"commentId" binding:"required"`
+	Reaction  string `json:"reaction" binding:"required"`
+}
+
+func ReactToComment(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json ReactToCommentRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Check that Reaction is valid
+	if !verifyReaction(json.Reaction) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Invalid reaction."})
+		return
+	}
+
+	// Find Comment from CommentID
+	var comment models.Comment
+	database.DB.First(&comment, json.CommentID)
+	if comment.ID == 0 || (comment.Hidden && !auth.CanSeeHiddenContent(&user, comment.UserID)) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Comment not found."})
+		return
+	}
+
+	if comment.UserID == user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You cannot react to your own comment."})
+		return
+	}
+
+	// Create or change Reaction and update votes together
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockCommentVotes(tx, comment.ID); err != nil {
+			return err
+		}
+
+		var reaction models.Reaction
+		tx.Where("comment_id = ? AND user_id = ?", comment.ID, user.ID).Limit(1).Find(&reaction)
+		if reaction.ID == 0 {
+			if err := tx.Create(&models.Reaction{CommentID: comment.ID, UserID: user.ID, Type: json.Reaction}).Error; err != nil {
+				return err
+			}
+		} else if reaction.Type != json.Reaction {
+			if err := tx.Model(&reaction).Update("type", json.Reaction).Error; err != nil {
+				return err
+			}
+		}
+		return updateCommentVotes(tx, comment.ID)
+	})
+
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to react to comment. Try again later."})
+		return
+	}
+
+	// Return updated Comment
+	database.DB.Preload("Attachments").First(&comment, comment.ID)
+	c.JSON(http.StatusAccepted, CreateCommentResponse(&comment, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*             RemoveReaction | route: comments/unreact/:commentId            */
+/* -------------------------------------------------------------------------- */
+type RemoveReactionRequest struct {
+	CommentID uint `uri:"commentId" binding:"required"`
+}
+
+func RemoveReaction(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json RemoveReactionRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Comment from CommentID
+	var comment models.Comment
+	database.DB.First(&comment, json.CommentID)
+	if comment.ID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Comment not found."})
+		return
+	}
+
+	// Delete Reaction and update votes together
+	var notReacted bool
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockCommentVotes(tx, comment.ID); err != nil {
+			return err
+		}
+
+		result := tx.Where("comment_id = ? AND user_id = ?", comment.ID, user.ID).Delete(&models.Reaction{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			notReacted = true
+			return nil
+		}
+		return updateCommentVotes(tx, comment.ID)
+	})
+
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to remove reaction. Try again later."})
+		return
+	}
+	if notReacted {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You have not reacted to this comment."})
+		return
+	}
+
+	// Return updated Comment
+	database.DB.Preload("Attachments").First(&comment, comment.ID)
+	c.JSON(http.StatusAccepted, CreateCommentResponse(&comment, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                    AcceptAnswer | route: comments/accept                   */
+/* -------------------------------------------------------------------------- */
+type AcceptAnswerRequest struct {
+	CommentID uint `json:"commentId" binding:"required"`
+}
+
+func AcceptAnswer(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json AcceptAnswerRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Comment and its Post, check that RequestUser can accept answers
+	comment, post, found := findAnswerPost(c, &user, json.CommentID)
+	if found == false {
+		return
+	}
+
+	// Accept Comment, replacing the previously accepted answer (if any)
+	post.AcceptedCommentID = &comment.ID
+	database.DB.Model(&post).UpdateColumn("accepted_comment_id", post.AcceptedCommentID)
+
+	// Anonymous authors accepting an answer stay anonymous
+	notifications.Notify(comment.UserID, &user, config.NOTIFICATION_ANSWER, post.ID, &comment.ID, post.Anonymous && post.UserID == user.ID)
+
+	fmt.Printf("%s has accepted an answer.\n\tPost title: %s\n\tComment text: %s\n", user.Username, post.Title, comment.Text)
+
+	// Return accepted Comment
+	database.DB.Preload("Attachments").First(&comment, comment.ID)
+	c.JSON(http.StatusAccepted, CreateCommentResponse(&comment, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*            UnacceptAnswer | route: comments/unaccept/:commentId            */
+/* -------------------------------------------------------------------------- */
+type UnacceptAnswerRequest struct {
+	CommentID uint `uri:"commentId" binding:"required"`
+}
+
+func UnacceptAnswer(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json UnacceptAnswerRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Comment and its Post, check that RequestUser can accept answers
+	comment, post, found := findAnswerPost(c, &user, json.CommentID)
+	if found == false {
+		return
+	}
+
+	if post.AcceptedCommentID == nil || *post.AcceptedCommentID != comment.ID {
+		c.JSON(http.StatusForbidden, gin.H{"message": "This comment is not the accepted answer."})
+		return
+	}
+
+	// Post is unanswered again
+	database.DB.Model(&post).UpdateColumn("accepted_comment_id", nil)
+
+	fmt.Printf("%s has unaccepted an answer.\n\tPost title: %s\n", user.Username, post.Title)
+
+	// Return unaccepted Comment
+	database.DB.Preload("Attachments").First(&comment, comment.ID)
+	c.JSON(http.StatusAccepted, CreateCommentResponse(&comment, &user))
 }
@@ -1,55 +1,567 @@
 package comments
 
 import (
+	"fmt"
 	"math"
+	"net/http"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/mfjkri/OneNUS-Backend/config"
+	"github.com/mfjkri/OneNUS-Backend/controllers/attachments"
+	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/controllers/notifications"
+	"github.com/mfjkri/OneNUS-Backend/database"
 	"github.com/mfjkri/OneNUS-Backend/models"
+	"github.com/mfjkri/OneNUS-Backend/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Message shown when trying to comment on a locked Post
+func lockedPostMessage(post *models.Post) string {
+	if post.LockedReason == "" {
+		return "This post has been locked and is no longer accepting comments."
+	}
+	return fmt.Sprintf("This post has been locked and is no longer accepting comments. Reason: %s", post.LockedReason)
+}
+
+// Finds a published Post whose Comments can be listed for user
+func findCommentsPost(c *gin.Context, user *models.User, postID uint) (models.Post, bool) {
+	var post models.Post
+	database.DB.First(&post, postID)
+
+	// Posts hidden by reports are only visible to their author and moderators
+	if post.ID == 0 || post.Status != config.POST_STATUS_PUBLISHED || (post.Hidden && !auth.CanSeeHiddenContent(user, post.UserID)) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Post not found."})
+		return post, false
+	}
+
+	// Posts of blocked Users are not visible (anonymous Posts are, so that blocking does not reveal their author)
+	if !post.Anonymous && auth.IsBlocked(user.ID, post.UserID) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Post not found."})
+		return post, false
+	}
+	return post, true
+}
+
+// Comments hidden by reports are only listed for their author and moderators.
+// Comments of blocked and muted Users are left out.
+func filterListedComments(dbContext *gorm.DB, user *models.User) *gorm.DB {
+	if !auth.IsModerator(user) {
+		dbContext = dbContext.Where("hidden = ? OR user_id = ?", false, user.ID)
+	}
+	return auth.FilterBlockedContent(dbContext, user)
+}
+
+/* -------------------------------------------------------------------------- */
+/*                                  Reactions                                 */
+/* -------------------------------------------------------------------------- */
+func verifyReaction(reaction string) bool {
+	return reaction == config.REACTION_UPVOTE || reaction == config.REACTION_DOWNVOTE
+}
+
+// Reactions of user to the given Comments, by CommentID
+func myReactions(commentIDs []uint, user *models.User) map[uint]string {
+	reactionsByComment := map[uint]string{}
+	if len(commentIDs) == 0 {
+		return reactionsByComment
+	}
+
+	var reactions []models.Reaction
+	database.DB.Where("user_id = ? AND comment_id IN ?", user.ID, commentIDs).Find(&reactions)
+	for _, reaction := range reactions {
+		reactionsByComment[reaction.CommentID] = reaction.Type
+	}
+	return reactionsByComment
+}
+
+// Locks a Comment until the end of tx so that concurrent Reactions to it are counted one after another.
+// It has to come first in tx, otherwise the counts of updateCommentVotes can miss Reactions committed in between.
+func lockCommentVotes(tx *gorm.DB, commentID uint) error {
+	var comment models.Comment
+	return tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", commentID).Find(&comment).Error
+}
+
+// Recounts the votes of a Comment from its Reactions and updates its Score (see lockCommentVotes).
+// UpdatedAt of the Comment is left untouched, it should only reflect changes to comment.Text
+func updateCommentVotes(tx *gorm.DB, commentID uint) error {
+	var upvotesCount, downvotesCount int64
+	tx.Model(&models.Reaction{}).Where("comment_id = ? AND type = ?", commentID, config.REACTION_UPVOTE).Count(&upvotesCount)
+	tx.Model(&models.Reaction{}).Where("comment_id = ? AND type = ?", commentID, config.REACTION_DOWNVOTE).Count(&downvotesCount)
+
+	return tx.Model(&models.Comment{}).Where("id = ?", commentID).UpdateColumns(map[string]interface{}{
+		"upvotes_count":   upvotesCount,
+		"downvotes_count": downvotesCount,
+		"score":           utils.WilsonLowerBound(uint(upvotesCount), uint(downvotesCount)),
+	}).Error
+}
+
+// Finds a Comment (deleted Comments are placeholders) and its Post if both are visible to user
+func findListedComment(c *gin.Context, user *models.User, commentID uint) (models.Comment, models.Post, bool) {
+	var comment models.Comment
+	database.DB.Unscoped().Preload("Attachments").First(&comment, commentID)
+	if comment.ID == 0 || (comment.Hidden && !auth.CanSeeHiddenContent(user, comment.UserID)) || (!comment.Anonymous && auth.IsBlocked(user.ID, comment.UserID)) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Comment not found."})
+		return comment, models.Post{}, false
+	}
+
+	post, found := findCommentsPost(c, user, comment.PostID)
+	return comment, post, found
+}
+
+// Finds the accepted answer of a Q&A Post if it is listed for user (see filterListedComments)
+func findAcceptedAnswer(post *models.Post, user *models.User) (models.Comment, bool) {
+	var comment models.Comment
+	if post.AcceptedCommentID != nil {
+		database.DB.Preload("Attachments").First(&comment, *post.AcceptedCommentID)
+	}
+	if comment.ID == 0 || !auth.CanSeeComment(user, &comment) || (!comment.Anonymous && auth.IsMuted(user.ID, comment.UserID)) {
+		return comment, false
+	}
+	return comment, true
+}
+
+// Finds a Comment of a Q&A Post whose accepted answer can be changed by user (the Post author or a moderator)
+func findAnswerPost(c *gin.Context, user *models.User, commentID uint) (models.Comment, models.Post, bool) {
+	var comment models.Comment
+	var post models.Post
+	database.DB.First(&comment, commentID)
+	if comment.ID == 0 || (comment.Hidden && !auth.CanSeeHiddenContent(user, comment.UserID)) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Comment not found."})
+		return comment, post, false
+	}
+
+	database.DB.First(&post, comment.PostID)
+	if post.ID == 0 || post.Status != config.POST_STATUS_PUBLISHED {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Post not found."})
+		return comment, post, false
+	}
+
+	if !config.QA_TAGS[post.Tag] {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Only questions can have an accepted answer."})
+		return comment, post, false
+	}
+
+	if post.UserID != user.ID && !auth.IsModerator(user) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You do not have valid permissions."})
+		return comment, post, false
+	}
+
+	return comment, post, true
+}
+
+// Renders Comment text into sanitized HTML and returns the resolved mentions.
+// Code highlighting follows the tag of the parent Post.
+func renderCommentText(comment *models.Comment, post *models.Post, author *models.User) map[string]uint {
+	mentions := notifications.ResolveMentions(comment.Text, author)
+	comment.TextHTML = utils.RenderMarkdownWithMentions(comment.Text, config.CODE_HIGHLIGHT_TAGS[post.Tag], mentions)
+	return mentions
+}
+
+// Saves the Mentions of a saved Comment and notifies newly mentioned Users
+func saveCommentMentions(comment *models.Comment, author *models.User, mentions map[string]uint) []uint {
+	newUserIDs := notifications.SaveMentions(comment.PostID, &comment.ID, mentions)
+	notifications.NotifyMentions(author, newUserIDs, comment.PostID, &comment.ID, comment.Anonymous)
+	return newUserIDs
+}
+
+// Text shown in place of soft deleted Comments
+const DeletedCommentText = "[deleted]"
+
+// Soft deletes a Comment. Its Attachments are kept until the Comment is purged.
+func RemoveComment(comment *models.Comment, deletedBy *models.User) {
+	comment.DeletedByID = deletedBy.ID
+
+	database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(comment).UpdateColumn("deleted_by_id", comment.DeletedByID).Error; err != nil {
+			return err
+		}
+
+		// A Comment that was deleted concurrently is only uncounted once
+		result := tx.Delete(comment)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		// Update associated Post metadata and CommentsCount of the author
+		if err := models.UpdatePostCommentsMetadata(tx, comment.PostID, -1); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{}).Where("id = ? AND comments_count > 0", comment.UserID).UpdateColumn("comments_count", gorm.Expr("comments_count - 1")).Error; err != nil {
+			return err
+		}
+
+		// Deleted Comments are no longer the accepted answer (restoring them does not accept them again)
+		return tx.Model(&models.Post{}).Where("id = ? AND accepted_comment_id = ?", comment.PostID, comment.ID).UpdateColumn("accepted_comment_id", nil).Error
+	})
+}
+
+// Finds a soft deleted Comment that user is allowed to restore.
+// Authors can only restore Comments they deleted themselves, admins can restore any Comment.
+// Comments of purged Users (see users.PurgeDeletedUsers) have no author left and cannot be restored.
+func findDeletedComment(c *gin.Context, user *models.User, commentID uint) (models.Comment, bool) {
+	var comment models.Comment
+	database.DB.Unscoped().Preload("Attachments").Where("deleted_at IS NOT NULL AND user_id IS NOT NULL").First(&comment, commentID)
+	if comment.ID == 0 || (user.Role != config.USER_ROLE_ADMIN && (comment.UserID != user.ID || comment.DeletedByID != user.ID)) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Deleted comment not found."})
+		return comment, false
+	}
+	return comment, true
+}
+
+// Restores a soft deleted Comment and updates the counters of its Post and author
+func restoreComment(comment *models.Comment) {
+	database.DB.Transaction(func(tx *gorm.DB) error {
+		// A Comment that was restored concurrently is only counted once
+		result := tx.Unscoped().Model(comment).Where("deleted_at IS NOT NULL").UpdateColumns(map[string]interface{}{
+			"deleted_at":    nil,
+			"deleted_by_id": 0,
+		})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		if err := models.UpdatePostCommentsMetadata(tx, comment.PostID, 1); err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", comment.UserID).UpdateColumn("comments_count", gorm.Expr("comments_count + 1")).Error
+	})
+	comment.DeletedAt = gorm.DeletedAt{}
+	comment.DeletedByID = 0
+}
+
+// Permanently deletes Comments (and their Attachments) that were soft deleted
+// more than config.TRASH_RETENTION_PERIOD ago.
+// Comments that still have replies are kept as placeholders until their replies are purged.
+func PurgeDeletedComments() {
+	var comments []models.Comment
+	database.DB.Unscoped().Where("deleted_at < ? AND replies_count = 0", time.Now().Add(-config.TRASH_RETENTION_PERIOD)).Find(&comments)
+
+	for _, comment := range comments {
+		attachments.DeleteAttachmentsFromContext(database.DB.Where("comment_id = ?", comment.ID))
+		database.DB.Unscoped().Delete(&comment)
+
+		if comment.ParentID != nil {
+			database.DB.Unscoped().Model(&models.Comment{}).Where("id = ? AND replies_count > 0", *comment.ParentID).UpdateColumn("replies_count", gorm.Expr("replies_count - 1"))
+		}
+	}
+
+	if len(comments) > 0 {
+		fmt.Printf("Purged %d deleted comments.\n", len(comments))
+	}
+}
+
 type CommentResponse struct {
-	ID     uint   `json:"id" binding:"required"`
-	Text   string `json:"text" binding:"required"`
-	Author string `json:"author" binding:"required"`
-	UserID uint   `json:"userId" binding:"required"`
+	ID        uint   `json:"id" binding:"required"`
+	Text      string `json:"text" binding:"required"`
+	TextHTML  string `json:"textHtml" binding:"required"`
+	Author    string `json:"author" binding:"required"`
+	UserID    uint   `json:"userId" binding:"required"`
+	Anonymous bool   `json:"anonymous" binding:"required"`
 
 	PostID uint `json:"postId" binding:"required"`
 
+	// ParentID is 0 for top level Comments
+	ParentID     uint `json:"parentId"`
+	Depth        uint `json:"depth"`
+	RepliesCount uint `json:"repliesCount"`
+
+	// MyReaction is empty if the RequestUser has not reacted to the Comment
+	Upvotes    uint   `json:"upvotes" binding:"required"`
+	Downvotes  uint   `json:"downvotes" binding:"required"`
+	MyReaction string `json:"myReaction"`
+
+	Attachments []attachments.AttachmentResponse `json:"attachments" binding:"required"`
+
+	Hidden    bool  `json:"hidden" binding:"required"`
+	Deleted   bool  `json:"deleted" binding:"required"`
+	DeletedAt int64 `json:"deletedAt"`
+
 	CreatedAt int64 `json:"createdAt" binding:"required"`
 	UpdatedAt int64 `json:"updatedAt" binding:"required"`
 }
 
-// Convert a Comment Model into a JSON format
-func CreateCommentResponse(comment *models.Comment) CommentResponse {
+// Convert a Comment Model into a JSON format as seen by user
+func CreateCommentResponse(comment *models.Comment, user *models.User) CommentResponse {
+	return createCommentResponse(comment, user, myReactions([]uint{comment.ID}, user)[comment.ID])
+}
+
+// Same as CreateCommentResponse with the reaction of user already looked up (see myReactions)
+func createCommentResponse(comment *models.Comment, user *models.User, myReaction string) CommentResponse {
+	// Anonymous Comments show a pseudonym and only reveal UserID to the author and moderators
+	author, userID := comment.Author, comment.UserID
+	attachmentsResponse := attachments.CreateAttachmentsResponse(comment.Attachments)
+	if comment.Anonymous {
+		author = utils.Pseudonym(comment.PostID, comment.UserID)
+		if !auth.CanSeeAnonymousAuthor(user, comment.UserID) {
+			userID = 0
+			for i := range attachmentsResponse {
+				attachmentsResponse[i].UserID = 0
+			}
+		}
+	}
+
+	// Deleted Comments are kept as placeholders so that threads stay readable.
+	// UserID is only revealed to the author and moderators so that they can restore it.
+	text, textHTML := comment.Text, comment.TextHTML
+	var deletedAt int64
+	if comment.DeletedAt.Valid {
+		text, textHTML, author = DeletedCommentText, "<p>"+DeletedCommentText+"</p>", DeletedCommentText
+		attachmentsResponse = []attachments.AttachmentResponse{}
+		deletedAt = comment.DeletedAt.Time.Unix()
+		if !auth.CanSeeHiddenContent(user, comment.UserID) {
+			userID = 0
+		}
+	}
+
+	var parentID uint
+	if comment.ParentID != nil {
+		parentID = *comment.ParentID
+	}
+
 	return CommentResponse{
-		ID:        comment.ID,
-		Text:      comment.Text,
-		Author:    comment.Author,
-		UserID:    comment.UserID,
-		PostID:    comment.PostID,
-		CreatedAt: comment.CreatedAt.Unix(),
-		UpdatedAt: comment.UpdatedAt.Unix(),
+		ID:           comment.ID,
+		Text:         text,
+		TextHTML:     textHTML,
+		Author:       author,
+		UserID:       userID,
+		Anonymous:    comment.Anonymous,
+		PostID:       comment.PostID,
+		ParentID:     parentID,
+		Depth:        comment.Depth,
+		RepliesCount: comment.RepliesCount,
+		Upvotes:      comment.UpvotesCount,
+		Downvotes:    comment.DownvotesCount,
+		MyReaction:   myReaction,
+		Attachments:  attachmentsResponse,
+		Hidden:       comment.Hidden,
+		Deleted:      comment.DeletedAt.Valid,
+		DeletedAt:    deletedAt,
+		CreatedAt:    comment.CreatedAt.Unix(),
+		UpdatedAt:    comment.UpdatedAt.Unix(),
 	}
 }
 
+// CommentsCount includes [deleted] placeholders, DeletedCommentsCount is how many of them there are.
+// CommentsCount of the Post only counts Comments that are not deleted.
 type GetCommentsResponse struct {
-	Comments      []CommentResponse `json:"comments" binding:"required"`
-	CommentsCount int64             `json:"commentsCount" binding:"required"`
+	Comments             []CommentResponse `json:"comments" binding:"required"`
+	CommentsCount        int64             `json:"commentsCount" binding:"required"`
+	DeletedCommentsCount int64             `json:"deletedCommentsCount" binding:"required"`
 }
 
 // Bundles and convert multiple comments models into a JSON format
-func CreateCommentsResponse(comments *[]models.Comment, totalCommentsCount int64) GetCommentsResponse {
+func CreateCommentsResponse(comments *[]models.Comment, totalCommentsCount int64, deletedCommentsCount int64, user *models.User) GetCommentsResponse {
+	var commentIDs []uint
+	for _, comment := range *comments {
+		commentIDs = append(commentIDs, comment.ID)
+	}
+	reactions := myReactions(commentIDs, user)
+
 	var commentsResponse []CommentResponse
 	for _, comment := range *comments {
-		commentResponse := CreateCommentResponse(&comment)
+		commentResponse := createCommentResponse(&comment, user, reactions[comment.ID])
 		commentsResponse = append(commentsResponse, commentResponse)
 	}
 
 	return GetCommentsResponse{
-		Comments:      commentsResponse,
-		CommentsCount: totalCommentsCount,
+		Comments:             commentsResponse,
+		CommentsCount:        totalCommentsCount,
+		DeletedCommentsCount: deletedCommentsCount,
+	}
+}
+
+type CommentTreeResponse struct {
+	CommentResponse
+	Replies []CommentTreeResponse `json:"replies" binding:"required"`
+}
+
+// Same counts as GetCommentsResponse, for top level Comments only
+type GetCommentTreeResponse struct {
+	Comments             []CommentTreeResponse `json:"comments" binding:"required"`
+	CommentsCount        int64                 `json:"commentsCount" binding:"required"`
+	DeletedCommentsCount int64                 `json:"deletedCommentsCount" binding:"required"`
+}
+
+// Replies below some Comments, grouped by ParentID, along with the reactions of the RequestUser
+type commentTree struct {
+	replies   map[uint][]models.Comment
+	reactions map[uint]string
+}
+
+// Loads all replies below comments (oldest first), grouped by ParentID.
+// Deleted replies are included as placeholders, hidden replies (and their replies) only for their author and moderators.
+func loadCommentTree(comments []models.Comment, user *models.User) *commentTree {
+	replies := map[uint][]models.Comment{}
+
+	var parentIDs []uint
+	for _, comment := range comments {
+		parentIDs = append(parentIDs, comment.ID)
 	}
+	commentIDs := parentIDs
+
+	// One query per level, nesting is limited by config.MAX_COMMENT_DEPTH
+	for len(parentIDs) > 0 {
+		var children []models.Comment
+		dbContext := database.DB.Unscoped().Preload("Attachments").Where("parent_id IN ?", parentIDs)
+		filterListedComments(dbContext, user).Order("created_at ASC, id ASC").Find(&children)
+
+		parentIDs = nil
+		for _, child := range children {
+			replies[*child.ParentID] = append(replies[*child.ParentID], child)
+			parentIDs = append(parentIDs, child.ID)
+		}
+		commentIDs = append(commentIDs, parentIDs...)
+	}
+
+	return &commentTree{replies: replies, reactions: myReactions(commentIDs, user)}
+}
+
+// Convert a Comment and its loaded replies into a nested JSON format
+func CreateCommentTreeResponse(comment *models.Comment, tree *commentTree, user *models.User) CommentTreeResponse {
+	repliesResponse := []CommentTreeResponse{}
+	for _, reply := range tree.replies[comment.ID] {
+		repliesResponse = append(repliesResponse, CreateCommentTreeResponse(&reply, tree, user))
+	}
+
+	return CommentTreeResponse{
+		CommentResponse: createCommentResponse(comment, user, tree.reactions[comment.ID]),
+		Replies:         repliesResponse,
+	}
+}
+
+// Convert a Comment and its loaded replies into a depth first list (see CommentResponse.Depth)
+func flattenCommentTree(commentsResponse []CommentResponse, comment *models.Comment, tree *commentTree, user *models.User) []CommentResponse {
+	commentsResponse = append(commentsResponse, createCommentResponse(comment, user, tree.reactions[comment.ID]))
+	for _, reply := range tree.replies[comment.ID] {
+		commentsResponse = flattenCommentTree(commentsResponse, &reply, tree, user)
+	}
+	return commentsResponse
+}
+
+// All Comments of post listed for user (deleted Comments are included as placeholders).
+// The accepted answer of a Q&A Post is left out, it is listed separately (see getCommentsPage).
+func postCommentsContext(post *models.Post, user *models.User, acceptedAnswer *models.Comment) *gorm.DB {
+	dbContext := filterListedComments(database.DB.Unscoped().Model(&models.Comment{}).Where("post_id = ?", post.ID), user)
+	if acceptedAnswer != nil {
+		dbContext = dbContext.Where("id <> ?", acceptedAnswer.ID)
+	}
+	return dbContext
+}
+
+// Top level Comments of post as listed by GetCommentTree (including [deleted] placeholders)
+func topLevelCommentsContext(post *models.Post, user *models.User) *gorm.DB {
+	return filterListedComments(database.DB.Unscoped().Model(&models.Comment{}).Where("post_id = ? AND parent_id IS NULL", post.ID), user)
+}
+
+// Counts the [deleted] placeholders among the Comments of a listing context
+func countDeletedComments(dbContext *gorm.DB) int64 {
+	var deletedCommentsCount int64
+	dbContext.Where("deleted_at IS NOT NULL").Count(&deletedCommentsCount)
+	return deletedCommentsCount
+}
+
+// Fetches a page of the Comments of post as listed by GetComments.
+// The accepted answer of a Q&A Post is listed first, so the first page has one less of the other Comments.
+func getCommentsPage(post *models.Post, user *models.User, perPage uint, pageNumber uint, sortOption string, sortOrder string) ([]models.Comment, int64) {
+	acceptedAnswer, answered := findAcceptedAnswer(post, user)
+	if !answered {
+		return GetCommentsFromContext(postCommentsContext(post, user, nil), perPage, pageNumber, sortOption, sortOrder)
+	}
+
+	// Limit PerPage to config.MAX_PER_PAGE
+	clampedPerPage := int64(math.Min(config.MAX_PER_PAGE, float64(perPage)))
+	offsetCommentsCount := int64(pageNumber-1) * clampedPerPage
+
+	// Get total count for Comments (including the accepted answer)
+	dbContext := postCommentsContext(post, user, &acceptedAnswer)
+	var otherCommentsCount int64
+	dbContext.Count(&otherCommentsCount)
+	totalCommentsCount := otherCommentsCount + 1
+
+	// If we are request beyond the bounds of total count, error
+	var comments []models.Comment
+	if (offsetCommentsCount < 0) || (offsetCommentsCount > totalCommentsCount) {
+		return comments, 0
+	}
+
+	if pageNumber == 1 {
+		comments = []models.Comment{acceptedAnswer}
+		if clampedPerPage > 1 {
+			comments = append(comments, getCommentsRange(dbContext, otherCommentsCount, 0, clampedPerPage-1, sortOption, sortOrder)...)
+		}
+	} else {
+		comments = getCommentsRange(dbContext, otherCommentsCount, offsetCommentsCount-1, clampedPerPage, sortOption, sortOrder)
+	}
+	return comments, totalCommentsCount
+}
+
+// Finds the position (from 0) of comment in the Comments of post as listed by GetComments
+// and the page that contains it
+func locateComment(comment *models.Comment, post *models.Post, user *models.User, perPage uint, sortOption string, sortOrder string) (int64, uint) {
+	acceptedAnswer, answered := findAcceptedAnswer(post, user)
+	if answered && acceptedAnswer.ID == comment.ID {
+		return 0, 1
+	}
+
+	var acceptedAnswerPtr *models.Comment
+	if answered {
+		acceptedAnswerPtr = &acceptedAnswer
+	}
+
+	// Count the Comments listed before comment in descending order
+	var totalCommentsCount, beforeCount int64
+	postCommentsContext(post, user, acceptedAnswerPtr).Count(&totalCommentsCount)
+	dbContext := postCommentsContext(post, user, acceptedAnswerPtr)
+	if sortOption == "top" {
+		dbContext = dbContext.Where("score > ? OR (score = ? AND (created_at > ? OR (created_at = ? AND id > ?)))", comment.Score, comment.Score, comment.CreatedAt, comment.CreatedAt, comment.ID)
+	} else {
+		dbContext = dbContext.Where("created_at > ? OR (created_at = ? AND id > ?)", comment.CreatedAt, comment.CreatedAt, comment.ID)
+	}
+	dbContext.Count(&beforeCount)
+
+	position := beforeCount
+	if sortOrder == "ascending" {
+		position = totalCommentsCount - 1 - beforeCount
+	}
+
+	// Accepted answer is listed before everything else (see getCommentsPage)
+	if answered {
+		position += 1
+	}
+
+	// Limit PerPage to config.MAX_PER_PAGE
+	clampedPerPage := int64(math.Min(config.MAX_PER_PAGE, float64(perPage)))
+	pageNumber := uint(position/clampedPerPage) + 1
+	return position, pageNumber
+}
+
+// Finds the top level Comment of the thread that comment belongs to
+func findThreadRoot(comment *models.Comment) models.Comment {
+	root := *comment
+	for root.ParentID != nil {
+		var parent models.Comment
+		database.DB.Unscoped().First(&parent, *root.ParentID)
+		if parent.ID == 0 {
+			break
+		}
+		root = parent
+	}
+	return root
+}
+
+type GetCommentsAroundResponse struct {
+	Comments             []CommentResponse `json:"comments" binding:"required"`
+	CommentsCount        int64             `json:"commentsCount" binding:"required"`
+	DeletedCommentsCount int64             `json:"deletedCommentsCount" binding:"required"`
+
+	// Page (of PerPage Comments) that contains the Comment and its position (from 0) across all pages
+	PageNumber uint  `json:"pageNumber" binding:"required"`
+	Position   int64 `json:"position" binding:"required"`
+
+	// Top level Comment of the thread (see comments/thread)
+	RootID uint `json:"rootId" binding:"required"`
 }
 
 // Fetches comments based on provided configuration
@@ -69,27 +581,39 @@ func GetCommentsFromContext(dbContext *gorm.DB, perPage uint, pageNumber uint, s
 		return comments, 0
 	}
 
-	// Sort Comments by sort option provided (defaults to byNew)
+	comments = getCommentsRange(dbContext, totalCommentsCount, offsetCommentsCount, clampedPerPage, sortOption, sortOrder)
+	return comments, totalCommentsCount
+}
+
+// Fetches limit Comments of dbContext (of totalCommentsCount Comments) starting from offset
+// in the order given by SortOption and SortOrder
+func getCommentsRange(dbContext *gorm.DB, totalCommentsCount int64, offset int64, limit int64, sortOption string, sortOrder string) []models.Comment {
+	var comments []models.Comment
+
+	// Sort Comments by sort option provided (defaults to byNew).
+	// Comments have no CommentedAt, so "recent" and "hot" are not supported.
 	defaultSortOption := config.SORT_BYNEW
-	if sortOption == "recent" {
-		defaultSortOption = config.SORT_BYRECENT
+	if sortOption == "top" {
+		defaultSortOption = config.SORT_BYTOP
 	}
 
-	// Fetch Comments from [offsetCount, offsetCount + perPage]
+	// Fetch Comments from [offset, offset + limit]
 	// results order depends on SortOption and SortOrder
 	if sortOrder == "ascending" {
-		// Reverse page number based on totalPostsCount
-		leftOverRecords := math.Min(float64(clampedPerPage), float64(totalCommentsCount-offsetCommentsCount))
-		offsetCommentsCount = totalCommentsCount - offsetCommentsCount - clampedPerPage
-		dbContext.Limit(int(leftOverRecords)).Order(defaultSortOption).Offset(int(offsetCommentsCount)).Find(&comments)
+		// Reverse offset based on totalCommentsCount
+		leftOverRecords := int64(math.Min(float64(limit), float64(totalCommentsCount-offset)))
+		if leftOverRecords <= 0 {
+			return comments
+		}
+		dbContext.Preload("Attachments").Limit(int(leftOverRecords)).Order(defaultSortOption).Offset(int(totalCommentsCount - offset - leftOverRecords)).Find(&comments)
 
 		// Reverse the page results for descending order
 		for i, j := 0, len(comments)-1; i < j; i, j = i+1, j-1 {
 			comments[i], comments[j] = comments[j], comments[i]
 		}
 	} else {
-		dbContext.Limit(int(clampedPerPage)).Order(defaultSortOption).Offset(int(offsetCommentsCount)).Find(&comments)
+		dbContext.Preload("Attachments").Limit(int(limit)).Order(defaultSortOption).Offset(int(offset)).Find(&comments)
 	}
 
-	return comments, totalCommentsCount
+	return comments
 }
@@ -4,7 +4,17 @@ import "github.com/gin-gonic/gin"
 
 func RegisterRoutes(r *gin.Engine) {
 	r.GET("comments/get/:postId/:perPage/:pageNumber/:sortOption/:sortOrder", GetComments)
+	r.GET("comments/tree/:postId/:perPage/:pageNumber/:sortOption/:sortOrder", GetCommentTree)
+	r.GET("comments/flat/:postId/:perPage/:pageNumber/:sortOption/:sortOrder", GetFlatCommentTree)
+	r.GET("comments/thread/:commentId", GetCommentThread)
+	r.GET("comments/getbyid/:commentId", GetCommentByID)
+	r.GET("comments/around/:commentId/:perPage/:sortOption/:sortOrder", GetCommentsAround)
 	r.POST("comments/create", CreateComment)
 	r.POST("comments/updatetext", UpdateCommentText)
 	r.DELETE("comments/delete/:commentId", DeleteComment)
+	r.POST("comments/restore", RestoreComment)
+	r.POST("comments/react", ReactToComment)
+	r.DELETE("comments/unreact/:commentId", RemoveReaction)
+	r.POST("comments/accept", AcceptAnswer)
+	r.DELETE("comments/unaccept/:commentId", UnacceptAnswer)
 }
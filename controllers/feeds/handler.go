@@ -0,0 +1,152 @@
+package feeds
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mfjkri/OneNUS-Backend/config"
+	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/controllers/posts"
+	"github.com/mfjkri/OneNUS-Backend/database"
+	"github.com/mfjkri/OneNUS-Backend/models"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                     GetFeedToken | route: /feeds/token                     */
+/* -------------------------------------------------------------------------- */
+func GetFeedToken(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Create a feed token on first use
+	if user.FeedToken == nil {
+		if resetFeedToken(c, &user) == false {
+			return
+		}
+	}
+
+	c.JSON(http.StatusAccepted, FeedTokenResponse{Token: *user.FeedToken})
+}
+
+/* -------------------------------------------------------------------------- */
+/*                  ResetFeedToken | route: /feeds/resettoken                 */
+/* -------------------------------------------------------------------------- */
+func ResetFeedToken(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Replace feed token, previous feed URLs stop working
+	if resetFeedToken(c, &user) == false {
+		return
+	}
+
+	fmt.Printf("%s has reset their feed token.\n", user.Username)
+
+	c.JSON(http.StatusAccepted, FeedTokenResponse{Token: *user.FeedToken})
+}
+
+/* -------------------------------------------------------------------------- */
+/*                 GetAllPostsFeed | route: /feeds/all/:format                */
+/* -------------------------------------------------------------------------- */
+type GetAllPostsFeedRequest struct {
+	Format string `uri:"format" binding:"required,oneof=rss atom"`
+}
+
+func GetAllPostsFeed(c *gin.Context) {
+	// Check that the feed token is valid
+	user, found := verifyFeedToken(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetAllPostsFeedRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Fetch latest Posts
+	latestPosts, _ := posts.GetPostsFromContext(posts.ListedPostsContext(&user), config.FEED_ITEMS_COUNT, 1, "", "")
+
+	writeFeed(c, json.Format, "OneNUS", "Latest posts on OneNUS", "/", latestPosts)
+}
+
+/* -------------------------------------------------------------------------- */
+/*                 GetTagFeed | route: /feeds/tag/:tag/:format                */
+/* -------------------------------------------------------------------------- */
+type GetTagFeedRequest struct {
+	Tag    string `uri:"tag" binding:"required"`
+	Format string `uri:"format" binding:"required,oneof=rss atom"`
+}
+
+func GetTagFeed(c *gin.Context) {
+	// Check that the feed token is valid
+	user, found := verifyFeedToken(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetTagFeedRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Check that the Tag is valid
+	if !posts.VerifyTag(json.Tag) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Unknown tag."})
+		return
+	}
+
+	// Fetch latest Posts with Tag
+	dbContext := posts.ListedPostsContext(&user).Where("tag = ?", json.Tag)
+	latestPosts, _ := posts.GetPostsFromContext(dbContext, config.FEED_ITEMS_COUNT, 1, "", "")
+
+	writeFeed(c, json.Format, "OneNUS - "+json.Tag, "Latest "+json.Tag+" posts on OneNUS", "/?tag="+json.Tag, latestPosts)
+}
+
+/* -------------------------------------------------------------------------- */
+/*              GetUserFeed | route: /feeds/user/:userId/:format              */
+/* -------------------------------------------------------------------------- */
+type GetUserFeedRequest struct {
+	UserID uint   `uri:"userId" binding:"required"`
+	Format string `uri:"format" binding:"required,oneof=rss atom"`
+}
+
+func GetUserFeed(c *gin.Context) {
+	// Check that the feed token is valid
+	user, found := verifyFeedToken(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetUserFeedRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Private Users have no feed
+	var targetUser models.User
+	database.DB.First(&targetUser, json.UserID)
+	if targetUser.ID == 0 || targetUser.Private {
+		c.JSON(http.StatusNotFound, gin.H{"message": "User not found."})
+		return
+	}
+
+	// Fetch latest Posts by User (anonymous Posts are left out)
+	dbContext := posts.ListedPostsContext(&user).Where("user_id = ? AND anonymous = ?", targetUser.ID, false)
+	latestPosts, _ := posts.GetPostsFromContext(dbContext, config.FEED_ITEMS_COUNT, 1, "", "")
+
+	writeFeed(c, json.Format, "OneNUS - "+targetUser.Username, "Latest posts by "+targetUser.Username+" on OneNUS", fmt.Sprintf("/users/%d", targetUser.ID), latestPosts)
+}
@@ -0,0 +1,256 @@
+package feeds
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mfjkri/OneNUS-Backend/database"
+	"github.com/mfjkri/OneNUS-Backend/models"
+	"github.com/mfjkri/OneNUS-Backend/utils"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                 Feed tokens                                */
+/* -------------------------------------------------------------------------- */
+// Generates a new random feed token
+func generateFeedToken() (string, error) {
+	token := make([]byte, 24)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// Gives user a new feed token, invalidating the previous one (if any)
+func resetFeedToken(c *gin.Context, user *models.User) bool {
+	token, err := generateFeedToken()
+	if err != nil {
+		c.JSON(http.StatusExpectationFailed, gin.H{"message": "Failed to create feed token."})
+		return false
+	}
+
+	user.FeedToken = &token
+	database.DB.Model(user).UpdateColumn("feed_token", user.FeedToken)
+	return true
+}
+
+// Finds the User that owns the ?token= of a feed request
+func verifyFeedToken(c *gin.Context) (models.User, bool) {
+	var user models.User
+	token := c.Query("token")
+	if token != "" {
+		database.DB.Where("feed_token = ?", token).First(&user)
+	}
+	if user.ID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Invalid feed token."})
+		return user, false
+	}
+	return user, true
+}
+
+type FeedTokenResponse struct {
+	Token string `json:"token" binding:"required"`
+}
+
+/* -------------------------------------------------------------------------- */
+/*                                    Feeds                                   */
+/* -------------------------------------------------------------------------- */
+const (
+	FEED_FORMAT_RSS  = "rss"
+	FEED_FORMAT_ATOM = "atom"
+)
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Category    string  `xml:"category"`
+	Description string  `xml:"description"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title     string       `xml:"title"`
+	ID        string       `xml:"id"`
+	Link      atomLink     `xml:"link"`
+	Published string       `xml:"published"`
+	Updated   string       `xml:"updated"`
+	Author    atomAuthor   `xml:"author"`
+	Category  atomCategory `xml:"category"`
+	Content   atomContent  `xml:"content"`
+}
+
+// Link to a page of the frontend
+func frontendURL(path string) string {
+	return strings.TrimRight(os.Getenv("FRONTEND_URL"), "/") + path
+}
+
+// Author shown for a Post (pseudonym for anonymous Posts)
+func postAuthor(post *models.Post) string {
+	if post.Anonymous {
+		return utils.Pseudonym(post.ID, post.UserID)
+	}
+	return post.Author
+}
+
+// Builds an RSS 2.0 or Atom document of posts
+func buildFeed(format string, title string, description string, path string, posts []models.Post, lastModified time.Time) ([]byte, error) {
+	var feed interface{}
+	if format == FEED_FORMAT_ATOM {
+		var entries []atomEntry
+		for _, post := range posts {
+			postURL := frontendURL(fmt.Sprintf("/posts/%d", post.ID))
+			entries = append(entries, atomEntry{
+				Title:     post.Title,
+				ID:        postURL,
+				Link:      atomLink{Href: postURL, Rel: "alternate"},
+				Published: post.CreatedAt.UTC().Format(time.RFC3339),
+				Updated:   post.UpdatedAt.UTC().Format(time.RFC3339),
+				Author:    atomAuthor{Name: postAuthor(&post)},
+				Category:  atomCategory{Term: post.Tag},
+				Content:   atomContent{Type: "html", Body: post.TextHTML},
+			})
+		}
+
+		feed = atomFeed{
+			Title:   title,
+			ID:      frontendURL(path),
+			Updated: lastModified.UTC().Format(time.RFC3339),
+			Link:    atomLink{Href: frontendURL(path), Rel: "alternate"},
+			Entries: entries,
+		}
+	} else {
+		var items []rssItem
+		for _, post := range posts {
+			postURL := frontendURL(fmt.Sprintf("/posts/%d", post.ID))
+			items = append(items, rssItem{
+				Title:       post.Title,
+				Link:        postURL,
+				GUID:        rssGUID{IsPermaLink: true, Value: postURL},
+				PubDate:     post.CreatedAt.UTC().Format(time.RFC1123Z),
+				Category:    post.Tag,
+				Description: fmt.Sprintf("<p>Posted by %s</p>%s", postAuthor(&post), post.TextHTML),
+			})
+		}
+
+		feed = rssFeed{
+			Version: "2.0",
+			Channel: rssChannel{
+				Title:         title,
+				Link:          frontendURL(path),
+				Description:   description,
+				LastBuildDate: lastModified.UTC().Format(time.RFC1123Z),
+				Items:         items,
+			},
+		}
+	}
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// Writes posts as a feed with ETag and Last-Modified headers.
+// Responds with 304 Not Modified if the feed reader already has the current version.
+func writeFeed(c *gin.Context, format string, title string, description string, path string, posts []models.Post) {
+	// Feed was last modified by its most recently created or updated Post
+	lastModified := time.Unix(0, 0)
+	for _, post := range posts {
+		if post.UpdatedAt.After(lastModified) {
+			lastModified = post.UpdatedAt
+		}
+		if post.CreatedAt.After(lastModified) {
+			lastModified = post.CreatedAt
+		}
+	}
+	lastModified = lastModified.UTC().Truncate(time.Second)
+
+	body, err := buildFeed(format, title, description, path, posts, lastModified)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to build feed."})
+		return
+	}
+
+	hash := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(hash[:16]) + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	c.Header("Cache-Control", "private, max-age=300")
+
+	// If-None-Match takes precedence over If-Modified-Since
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				c.Status(http.StatusNotModified)
+				return
+			}
+		}
+	} else if ifModifiedSince, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil && !lastModified.After(ifModifiedSince) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	contentType := "application/rss+xml; charset=utf-8"
+	if format == FEED_FORMAT_ATOM {
+		contentType = "application/atom+xml; charset=utf-8"
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
@@ -0,0 +1,15 @@
+package feeds
+
+import "github.com/gin-gonic/gin"
+
+func RegisterRoutes(r *gin.Engine) {
+	r.GET("feeds/token", GetFeedToken)
+	r.POST("feeds/resettoken", ResetFeedToken)
+}
+
+// Feed readers authenticate with ?token= instead of a JWT
+func RegisterPublicRoutes(r *gin.Engine) {
+	r.GET("feeds/all/:format", GetAllPostsFeed)
+	r.GET("feeds/tag/:tag/:format", GetTagFeed)
+	r.GET("feeds/user/:userId/:format", GetUserFeed)
+}
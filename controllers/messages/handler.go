@@ -0,0 +1,393 @@
+package messages
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mfjkri/OneNUS-Backend/config"
+	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/database"
+	"github.com/mfjkri/OneNUS-Backend/models"
+	"github.com/mfjkri/OneNUS-Backend/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                 StartConversation | route: /messages/start                 */
+/* -------------------------------------------------------------------------- */
+type StartConversationRequest struct {
+	// Other Users to talk to, more than one starts a group Conversation
+	UserIDs []uint `json:"userIds" binding:"required"`
+	Text    string `json:"text" binding:"required"`
+
+	// Optional: only used for group Conversations
+	Title string `json:"title"`
+}
+
+func StartConversation(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json StartConversationRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Other Participants, without duplicates
+	var userIDs []uint
+	seen := map[uint]bool{user.ID: true}
+	for _, userID := range json.UserIDs {
+		if !seen[userID] {
+			seen[userID] = true
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	if len(userIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "A conversation needs at least one other user."})
+		return
+	}
+	if len(userIDs)+1 > config.MAX_CONVERSATION_PARTICIPANTS {
+		c.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("Conversations cannot have more than %d participants.", config.MAX_CONVERSATION_PARTICIPANTS)})
+		return
+	}
+
+	// Check that every User exists and can be messaged by RequestUser
+	var users []models.User
+	database.DB.Where("id IN ?", userIDs).Find(&users)
+	if len(users) != len(userIDs) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "User not found."})
+		return
+	}
+	for _, targetUser := range users {
+		if targetUser.Private {
+			c.JSON(http.StatusForbidden, gin.H{"message": "This user is private."})
+			return
+		}
+		if auth.IsBlocked(user.ID, targetUser.ID) {
+			c.JSON(http.StatusForbidden, gin.H{"message": "You cannot message this user."})
+			return
+		}
+	}
+
+	// Prevent frequent SendMessages by User
+	timeNow, canSendMessage := utils.CheckTimeIsAfter(user.LastMessageAt, config.USER_MESSAGE_COOLDOWN)
+	if canSendMessage == false {
+		cdLeft := utils.GetCooldownLeft(user.LastMessageAt, config.USER_MESSAGE_COOLDOWN, timeNow)
+		c.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("Sending messages too frequently. Please try again in %ds", int(cdLeft.Seconds()))})
+		return
+	}
+
+	// Check that Text and Title are valid
+	text, err := utils.ValidateText("Message", json.Text, config.MAX_MESSAGE_TEXT_CHAR)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		return
+	}
+
+	isGroup := len(users) > 1
+	var title string
+	if isGroup && utils.NormalizeText(json.Title) != "" {
+		title, err = utils.ValidateLine("Title", json.Title, config.MAX_CONVERSATION_TITLE_CHAR)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+			return
+		}
+	}
+
+	// One-to-one Conversations are reused, group Conversations are always new
+	var conversation models.Conversation
+	var conversationKey *string
+	if !isGroup {
+		conversation = findDirectConversation(user.ID, users[0].ID)
+		key := directKey(user.ID, users[0].ID)
+		conversationKey = &key
+	}
+
+	// Create Conversation (if needed) and its first Message together
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if conversation.ID == 0 {
+			conversation = models.Conversation{
+				Title:        title,
+				IsGroup:      isGroup,
+				DirectKey:    conversationKey,
+				Participants: []models.Participant{{UserID: user.ID}},
+			}
+			for _, targetUser := range users {
+				conversation.Participants = append(conversation.Participants, models.Participant{UserID: targetUser.ID})
+			}
+
+			// The same one-to-one Conversation started concurrently fails on DirectKey, continue that one instead
+			if err := tx.Create(&conversation).Error; err != nil {
+				if conversationKey == nil || !database.IsDuplicateKeyError(err) {
+					return err
+				}
+				conversation = models.Conversation{}
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("direct_key = ?", *conversationKey).First(&conversation).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		_, err := sendMessage(tx, &conversation, &user, text, timeNow)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to send message. Try again later."})
+		return
+	}
+
+	fmt.Printf("%s has started a conversation with %d users.\n", user.Username, len(users))
+
+	// Return Conversation data
+	database.DB.Preload("Participants.User").First(&conversation, conversation.ID)
+	c.JSON(http.StatusAccepted, CreateConversationResponse(&conversation, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                     SendMessage | route: /messages/send                    */
+/* -------------------------------------------------------------------------- */
+type SendMessageRequest struct {
+	ConversationID uint   `json:"conversationId" binding:"required"`
+	Text           string `json:"text" binding:"required"`
+}
+
+func SendMessage(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json SendMessageRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Conversation from ConversationID
+	conversation, _, found := findConversation(c, &user, json.ConversationID)
+	if found == false {
+		return
+	}
+
+	if len(conversation.Participants) < 2 {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Everyone else has left this conversation."})
+		return
+	}
+	if isDirectConversationBlocked(&conversation, &user) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You cannot message this user."})
+		return
+	}
+
+	// Prevent frequent SendMessages by User
+	timeNow, canSendMessage := utils.CheckTimeIsAfter(user.LastMessageAt, config.USER_MESSAGE_COOLDOWN)
+	if canSendMessage == false {
+		cdLeft := utils.GetCooldownLeft(user.LastMessageAt, config.USER_MESSAGE_COOLDOWN, timeNow)
+		c.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("Sending messages too frequently. Please try again in %ds", int(cdLeft.Seconds()))})
+		return
+	}
+
+	// Check that Text is valid
+	text, err := utils.ValidateText("Message", json.Text, config.MAX_MESSAGE_TEXT_CHAR)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		return
+	}
+
+	var message models.Message
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		message, err = sendMessage(tx, &conversation, &user, text, timeNow)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to send message. Try again later."})
+		return
+	}
+
+	fmt.Printf("%s has sent a message in conversation %d.\n", user.Username, conversation.ID)
+
+	// Return new Message data
+	c.JSON(http.StatusAccepted, CreateMessageResponse(&message))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                        GetConversations | route: ...                       */
+/* -------------------------------------------------------------------------- */
+// route: /messages/conversations/:perPage/:pageNumber
+type GetConversationsRequest struct {
+	PerPage    uint `uri:"perPage" binding:"required"`
+	PageNumber uint `uri:"pageNumber" binding:"required"`
+}
+
+func GetConversations(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetConversationsRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Return fetched Conversations
+	c.JSON(http.StatusAccepted, GetConversationsFromDB(&user, json.PerPage, json.PageNumber))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                          GetMessages | route: ...                          */
+/* -------------------------------------------------------------------------- */
+// route: /messages/list/:conversationId/:perPage/:beforeId
+type GetMessagesRequest struct {
+	ConversationID uint `uri:"conversationId" binding:"required"`
+	PerPage        uint `uri:"perPage" binding:"required"`
+	// 0 for the latest Messages, otherwise the NextCursor of the previous page
+	BeforeID uint `uri:"beforeId"`
+}
+
+func GetMessages(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetMessagesRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Conversation from ConversationID
+	conversation, _, found := findConversation(c, &user, json.ConversationID)
+	if found == false {
+		return
+	}
+
+	// Return fetched Messages
+	c.JSON(http.StatusAccepted, GetMessagesFromContext(listedMessagesContext(conversation.ID, &user), json.PerPage, json.BeforeID))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                    ReadMessages | route: /messages/read                    */
+/* -------------------------------------------------------------------------- */
+type ReadMessagesRequest struct {
+	ConversationID uint `json:"conversationId" binding:"required"`
+
+	// Optional: marks all Messages as read if empty
+	MessageID uint `json:"messageId"`
+}
+
+func ReadMessages(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json ReadMessagesRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Conversation from ConversationID
+	conversation, participant, found := findConversation(c, &user, json.ConversationID)
+	if found == false {
+		return
+	}
+
+	messageID := json.MessageID
+	if messageID == 0 {
+		database.DB.Model(&models.Message{}).Where("conversation_id = ?", conversation.ID).Select("COALESCE(MAX(id), 0)").Scan(&messageID)
+	} else {
+		var messagesCount int64
+		database.DB.Model(&models.Message{}).Where("id = ? AND conversation_id = ?", messageID, conversation.ID).Count(&messagesCount)
+		if messagesCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Message not found."})
+			return
+		}
+	}
+
+	// Messages are read in order, the read marker never moves back
+	database.DB.Model(&participant).UpdateColumn("last_read_message_id", gorm.Expr("GREATEST(last_read_message_id, ?)", messageID))
+
+	// Return Conversation data with the remaining unread count
+	database.DB.Preload("Participants.User").First(&conversation, conversation.ID)
+	c.JSON(http.StatusAccepted, CreateConversationResponse(&conversation, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*         LeaveConversation | route: /messages/leave/:conversationId         */
+/* -------------------------------------------------------------------------- */
+type LeaveConversationRequest struct {
+	ConversationID uint `uri:"conversationId" binding:"required"`
+}
+
+func LeaveConversation(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json LeaveConversationRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Conversation from ConversationID
+	conversation, participant, found := findConversation(c, &user, json.ConversationID)
+	if found == false {
+		return
+	}
+	conversationResponse := CreateConversationResponse(&conversation, &user)
+
+	// Remove Participant, the Conversation (and its Messages) is deleted once everyone has left.
+	// Locking the Conversation makes sure the last two Participants leaving at once do not both see the other one left.
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var lockedConversation models.Conversation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", conversation.ID).Find(&lockedConversation).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&participant).Error; err != nil {
+			return err
+		}
+
+		var participantsCount int64
+		if err := tx.Model(&models.Participant{}).Where("conversation_id = ?", conversation.ID).Count(&participantsCount).Error; err != nil {
+			return err
+		}
+		if participantsCount == 0 {
+			return tx.Delete(&conversation).Error
+		}
+
+		// A new one-to-one Conversation can be started once someone has left this one
+		return tx.Model(&conversation).UpdateColumn("direct_key", nil).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to leave conversation. Try again later."})
+		return
+	}
+
+	fmt.Printf("%s has left conversation %d.\n", user.Username, conversation.ID)
+
+	// Return left Conversation data
+	c.JSON(http.StatusAccepted, conversationResponse)
+}
@@ -0,0 +1,295 @@
+package messages
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mfjkri/OneNUS-Backend/config"
+	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/database"
+	"github.com/mfjkri/OneNUS-Backend/models"
+	"github.com/mfjkri/OneNUS-Backend/utils"
+	"gorm.io/gorm"
+)
+
+// Finds a Conversation that user takes part in, along with their Participant
+func findConversation(c *gin.Context, user *models.User, conversationID uint) (models.Conversation, models.Participant, bool) {
+	var conversation models.Conversation
+	var participant models.Participant
+
+	database.DB.Where("conversation_id = ? AND user_id = ?", conversationID, user.ID).Limit(1).Find(&participant)
+	if participant.ID != 0 {
+		database.DB.Preload("Participants.User").First(&conversation, conversationID)
+	}
+
+	if conversation.ID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Conversation not found."})
+		return conversation, participant, false
+	}
+	return conversation, participant, true
+}
+
+// Conversations that userID takes part in, as a subquery of conversation IDs
+func conversationsOf(userID uint) *gorm.DB {
+	return database.DB.Model(&models.Participant{}).Select("conversation_id").Where("user_id = ?", userID)
+}
+
+// Finds the one-to-one Conversation between two Users (ID is 0 if there is none)
+func findDirectConversation(userID uint, otherUserID uint) models.Conversation {
+	var conversation models.Conversation
+	database.DB.Where("is_group = ? AND id IN (?) AND id IN (?)", false, conversationsOf(userID), conversationsOf(otherUserID)).Limit(1).Find(&conversation)
+	return conversation
+}
+
+// DirectKey of the one-to-one Conversation between two Users
+func directKey(userID uint, otherUserID uint) string {
+	if userID > otherUserID {
+		userID, otherUserID = otherUserID, userID
+	}
+	return fmt.Sprintf("%d:%d", userID, otherUserID)
+}
+
+// Checks whether user has blocked (or is blocked by) another Participant of a one-to-one Conversation.
+// Blocked Users stay in group Conversations, their Messages are left out for each other instead.
+func isDirectConversationBlocked(conversation *models.Conversation, user *models.User) bool {
+	if conversation.IsGroup {
+		return false
+	}
+	for _, participant := range conversation.Participants {
+		if participant.UserID != user.ID && auth.IsBlocked(user.ID, participant.UserID) {
+			return true
+		}
+	}
+	return false
+}
+
+// Messages of a Conversation listed for user. Messages of blocked Users are left out.
+func listedMessagesContext(conversationID uint, user *models.User) *gorm.DB {
+	return database.DB.Model(&models.Message{}).Where("conversation_id = ? AND (user_id IS NULL OR user_id NOT IN (?))", conversationID, auth.BlockedUsersQuery(user.ID))
+}
+
+// Saves a Message by user in conversation, marks it as read for them and updates LastMessageAt of both
+func sendMessage(tx *gorm.DB, conversation *models.Conversation, user *models.User, text string, timeNow time.Time) (models.Message, error) {
+	message := models.Message{
+		ConversationID: conversation.ID,
+		UserID:         user.ID,
+		Text:           text,
+		TextHTML:       utils.RenderMarkdown(text, false),
+	}
+	if err := tx.Create(&message).Error; err != nil {
+		return message, err
+	}
+
+	if err := tx.Model(&models.Conversation{}).Where("id = ?", conversation.ID).UpdateColumn("last_message_at", timeNow).Error; err != nil {
+		return message, err
+	}
+	if err := tx.Model(&models.Participant{}).Where("conversation_id = ? AND user_id = ?", conversation.ID, user.ID).UpdateColumn("last_read_message_id", message.ID).Error; err != nil {
+		return message, err
+	}
+	if err := tx.Model(&models.User{}).Where("id = ?", user.ID).UpdateColumn("last_message_at", timeNow).Error; err != nil {
+		return message, err
+	}
+
+	message.User = *user
+	conversation.LastMessageAt = timeNow
+	return message, nil
+}
+
+type MessageResponse struct {
+	ID             uint   `json:"id" binding:"required"`
+	ConversationID uint   `json:"conversationId" binding:"required"`
+	UserID         uint   `json:"userId" binding:"required"`
+	Author         string `json:"author" binding:"required"`
+	Text           string `json:"text" binding:"required"`
+	TextHTML       string `json:"textHtml" binding:"required"`
+	CreatedAt      int64  `json:"createdAt" binding:"required"`
+}
+
+// Convert a Message Model (with its User) into a JSON format
+func CreateMessageResponse(message *models.Message) MessageResponse {
+	return MessageResponse{
+		ID:             message.ID,
+		ConversationID: message.ConversationID,
+		UserID:         message.UserID,
+		Author:         message.User.Username,
+		Text:           message.Text,
+		TextHTML:       message.TextHTML,
+		CreatedAt:      message.CreatedAt.Unix(),
+	}
+}
+
+type ParticipantResponse struct {
+	UserID   uint   `json:"userId" binding:"required"`
+	Username string `json:"username" binding:"required"`
+}
+
+type ConversationResponse struct {
+	ID            uint                  `json:"id" binding:"required"`
+	Title         string                `json:"title"`
+	IsGroup       bool                  `json:"isGroup" binding:"required"`
+	Participants  []ParticipantResponse `json:"participants" binding:"required"`
+	LastMessage   *MessageResponse      `json:"lastMessage"`
+	UnreadCount   int64                 `json:"unreadCount" binding:"required"`
+	LastMessageAt int64                 `json:"lastMessageAt" binding:"required"`
+	CreatedAt     int64                 `json:"createdAt" binding:"required"`
+}
+
+// Last Message listed for user in each of the given Conversations, by ConversationID
+func findLastMessages(conversationIDs []uint, user *models.User) map[uint]models.Message {
+	lastMessages := map[uint]models.Message{}
+	if len(conversationIDs) == 0 {
+		return lastMessages
+	}
+
+	lastMessageIDs := database.DB.Model(&models.Message{}).
+		Select("MAX(id)").
+		Where("conversation_id IN ? AND (user_id IS NULL OR user_id NOT IN (?))", conversationIDs, auth.BlockedUsersQuery(user.ID)).
+		Group("conversation_id")
+
+	var messages []models.Message
+	database.DB.Preload("User").Where("id IN (?)", lastMessageIDs).Find(&messages)
+	for _, message := range messages {
+		lastMessages[message.ConversationID] = message
+	}
+	return lastMessages
+}
+
+// Same as auth.CountUnreadMessages for each of the given Conversations, by ConversationID
+func countUnreadMessages(conversationIDs []uint, user *models.User) map[uint]int64 {
+	unreadCounts := map[uint]int64{}
+	if len(conversationIDs) == 0 {
+		return unreadCounts
+	}
+
+	var counts []struct {
+		ConversationID uint
+		Count          int64
+	}
+	database.DB.Model(&models.Message{}).
+		Select("messages.conversation_id, COUNT(*) AS count").
+		Joins("JOIN participants ON participants.conversation_id = messages.conversation_id AND participants.user_id = ?", user.ID).
+		Where("messages.conversation_id IN ? AND messages.id > participants.last_read_message_id AND (messages.user_id IS NULL OR (messages.user_id <> ? AND messages.user_id NOT IN (?)))", conversationIDs, user.ID, auth.BlockedUsersQuery(user.ID)).
+		Group("messages.conversation_id").
+		Scan(&counts)
+	for _, count := range counts {
+		unreadCounts[count.ConversationID] = count.Count
+	}
+	return unreadCounts
+}
+
+// Convert a Conversation Model (with its Participants and their Users) into a JSON format for user
+func CreateConversationResponse(conversation *models.Conversation, user *models.User) ConversationResponse {
+	conversationIDs := []uint{conversation.ID}
+	return createConversationResponse(conversation, findLastMessages(conversationIDs, user), countUnreadMessages(conversationIDs, user))
+}
+
+// Same as CreateConversationResponse but uses the last Messages and unread counts of a whole page, fetched at once
+func createConversationResponse(conversation *models.Conversation, lastMessages map[uint]models.Message, unreadCounts map[uint]int64) ConversationResponse {
+	participantsResponse := []ParticipantResponse{}
+	for _, participant := range conversation.Participants {
+		participantsResponse = append(participantsResponse, ParticipantResponse{UserID: participant.UserID, Username: participant.User.Username})
+	}
+
+	var lastMessageResponse *MessageResponse
+	if lastMessage, found := lastMessages[conversation.ID]; found {
+		messageResponse := CreateMessageResponse(&lastMessage)
+		lastMessageResponse = &messageResponse
+	}
+
+	return ConversationResponse{
+		ID:            conversation.ID,
+		Title:         conversation.Title,
+		IsGroup:       conversation.IsGroup,
+		Participants:  participantsResponse,
+		LastMessage:   lastMessageResponse,
+		UnreadCount:   unreadCounts[conversation.ID],
+		LastMessageAt: conversation.LastMessageAt.Unix(),
+		CreatedAt:     conversation.CreatedAt.Unix(),
+	}
+}
+
+type GetConversationsResponse struct {
+	Conversations      []ConversationResponse `json:"conversations" binding:"required"`
+	ConversationsCount int64                  `json:"conversationsCount" binding:"required"`
+}
+
+// Fetches a page of the Conversations of user (most recent Message first)
+func GetConversationsFromDB(user *models.User, perPage uint, pageNumber uint) GetConversationsResponse {
+	var conversations []models.Conversation
+
+	// Limit PerPage to config.MAX_PER_PAGE
+	clampedPerPage := int64(math.Min(config.MAX_PER_PAGE, float64(perPage)))
+	offsetConversationsCount := int64(pageNumber-1) * clampedPerPage
+
+	// Get total count for Conversations
+	dbContext := database.DB.Model(&models.Conversation{}).Where("id IN (?)", conversationsOf(user.ID))
+	var totalConversationsCount int64
+	dbContext.Count(&totalConversationsCount)
+
+	conversationsResponse := []ConversationResponse{}
+
+	// If we are request beyond the bounds of total count, return nothing
+	if (offsetConversationsCount < 0) || (offsetConversationsCount > totalConversationsCount) {
+		return GetConversationsResponse{Conversations: conversationsResponse, ConversationsCount: 0}
+	}
+
+	dbContext.Preload("Participants.User").Order("last_message_at DESC, id DESC").Limit(int(clampedPerPage)).Offset(int(offsetConversationsCount)).Find(&conversations)
+
+	// Fetch last Messages and unread counts of the whole page at once
+	var conversationIDs []uint
+	for _, conversation := range conversations {
+		conversationIDs = append(conversationIDs, conversation.ID)
+	}
+	lastMessages := findLastMessages(conversationIDs, user)
+	unreadCounts := countUnreadMessages(conversationIDs, user)
+
+	for _, conversation := range conversations {
+		conversationsResponse = append(conversationsResponse, createConversationResponse(&conversation, lastMessages, unreadCounts))
+	}
+
+	return GetConversationsResponse{
+		Conversations:      conversationsResponse,
+		ConversationsCount: totalConversationsCount,
+	}
+}
+
+type GetMessagesResponse struct {
+	Messages []MessageResponse `json:"messages" binding:"required"`
+	// BeforeID of the next (older) page, 0 if there are no older Messages
+	NextCursor uint `json:"nextCursor"`
+}
+
+// Fetches up to perPage Messages of a Conversation older than beforeID (newest first).
+// A beforeID of 0 fetches the latest Messages.
+func GetMessagesFromContext(dbContext *gorm.DB, perPage uint, beforeID uint) GetMessagesResponse {
+	var messages []models.Message
+
+	// Limit PerPage to config.MAX_PER_PAGE
+	clampedPerPage := int(math.Min(config.MAX_PER_PAGE, float64(perPage)))
+
+	if beforeID != 0 {
+		dbContext = dbContext.Where("id < ?", beforeID)
+	}
+
+	// Fetch one extra Message to know whether there is a next page
+	dbContext.Preload("User").Order("id DESC").Limit(clampedPerPage + 1).Find(&messages)
+
+	var nextCursor uint
+	if len(messages) > clampedPerPage {
+		messages = messages[:clampedPerPage]
+		nextCursor = messages[len(messages)-1].ID
+	}
+
+	messagesResponse := []MessageResponse{}
+	for _, message := range messages {
+		messagesResponse = append(messagesResponse, CreateMessageResponse(&message))
+	}
+
+	return GetMessagesResponse{
+		Messages:   messagesResponse,
+		NextCursor: nextCursor,
+	}
+}
@@ -0,0 +1,12 @@
+package messages
+
+import "github.com/gin-gonic/gin"
+
+func RegisterRoutes(r *gin.Engine) {
+	r.POST("messages/start", StartConversation)
+	r.POST("messages/send", SendMessage)
+	r.GET("messages/conversations/:perPage/:pageNumber", GetConversations)
+	r.GET("messages/list/:conversationId/:perPage/:beforeId", GetMessages)
+	r.POST("messages/read", ReadMessages)
+	r.DELETE("messages/leave/:conversationId", LeaveConversation)
+}
@@ -0,0 +1,73 @@
+package notifications
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/database"
+	"github.com/mfjkri/OneNUS-Backend/models"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                        GetNotifications | route: ...                       */
+/* -------------------------------------------------------------------------- */
+// route: /notifications/get/:perPage/:pageNumber
+type GetNotificationsRequest struct {
+	PerPage    uint `uri:"perPage" binding:"required"`
+	PageNumber uint `uri:"pageNumber" binding:"required"`
+}
+
+func GetNotifications(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetNotificationsRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Return fetched Notifications
+	c.JSON(http.StatusAccepted, GetNotificationsFromDB(&user, json.PerPage, json.PageNumber))
+}
+
+/* -------------------------------------------------------------------------- */
+/*               ReadNotifications | route: /notifications/read               */
+/* -------------------------------------------------------------------------- */
+type ReadNotificationsRequest struct {
+	// Optional: marks all Notifications as read if empty
+	NotificationIDs []uint `json:"notificationIds"`
+}
+
+func ReadNotifications(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json ReadNotificationsRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Mark unread Notifications of RequestUser as read
+	dbContext := database.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", user.ID)
+	if len(json.NotificationIDs) > 0 {
+		dbContext = dbContext.Where("id IN ?", json.NotificationIDs)
+	}
+	dbContext.UpdateColumn("read_at", time.Now())
+
+	// Return remaining unread count
+	var unreadCount int64
+	database.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", user.ID).Count(&unreadCount)
+	c.JSON(http.StatusAccepted, ReadNotificationsResponse{UnreadCount: unreadCount})
+}
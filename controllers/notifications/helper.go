@@ -0,0 +1,237 @@
+package notifications
+
+import (
+	"math"
+
+	"github.com/mfjkri/OneNUS-Backend/config"
+	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/database"
+	"github.com/mfjkri/OneNUS-Backend/models"
+	"github.com/mfjkri/OneNUS-Backend/utils"
+	"gorm.io/gorm"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                  Mentions                                  */
+/* -------------------------------------------------------------------------- */
+// Resolves the usernames mentioned in text to the Users that author can mention.
+// Private Users and Users blocked by (or blocking) author cannot be mentioned.
+func ResolveMentions(text string, author *models.User) map[string]uint {
+	mentions := map[string]uint{}
+
+	usernames := utils.ExtractMentions(text)
+	if len(usernames) > config.MAX_MENTIONS_PER_TEXT {
+		usernames = usernames[:config.MAX_MENTIONS_PER_TEXT]
+	}
+	if len(usernames) == 0 {
+		return mentions
+	}
+
+	var users []models.User
+	database.DB.Where("username IN ? AND private = ? AND id NOT IN (?)", usernames, false, auth.BlockedUsersQuery(author.ID)).Find(&users)
+	for _, user := range users {
+		mentions[user.Username] = user.ID
+	}
+	return mentions
+}
+
+// Replaces the Mentions of a Post (or of one of its Comments if commentID is set).
+// Returns the Users that were not mentioned before.
+func SaveMentions(postID uint, commentID *uint, mentions map[string]uint) []uint {
+	dbContext := func() *gorm.DB {
+		if commentID != nil {
+			return database.DB.Where("post_id = ? AND comment_id = ?", postID, *commentID)
+		}
+		return database.DB.Where("post_id = ? AND comment_id IS NULL", postID)
+	}
+
+	var existingUserIDs []uint
+	dbContext().Model(&models.Mention{}).Pluck("user_id", &existingUserIDs)
+	existing := map[uint]bool{}
+	for _, userID := range existingUserIDs {
+		existing[userID] = true
+	}
+
+	var mentionedUserIDs []uint
+	var newUserIDs []uint
+	for _, userID := range mentions {
+		mentionedUserIDs = append(mentionedUserIDs, userID)
+		if !existing[userID] {
+			newUserIDs = append(newUserIDs, userID)
+			database.DB.Create(&models.Mention{UserID: userID, PostID: postID, CommentID: commentID})
+		}
+	}
+
+	// Remove Mentions that are no longer in the text
+	if len(mentionedUserIDs) > 0 {
+		dbContext().Where("user_id NOT IN ?", mentionedUserIDs).Delete(&models.Mention{})
+	} else {
+		dbContext().Delete(&models.Mention{})
+	}
+
+	return newUserIDs
+}
+
+// Users mentioned in a Post (or in one of its Comments if commentID is set)
+func MentionedUserIDs(postID uint, commentID *uint) []uint {
+	var userIDs []uint
+	dbContext := database.DB.Model(&models.Mention{}).Where("post_id = ?", postID)
+	if commentID != nil {
+		dbContext = dbContext.Where("comment_id = ?", *commentID)
+	} else {
+		dbContext = dbContext.Where("comment_id IS NULL")
+	}
+	dbContext.Pluck("user_id", &userIDs)
+	return userIDs
+}
+
+/* -------------------------------------------------------------------------- */
+/*                                Subscriptions                               */
+/* -------------------------------------------------------------------------- */
+// Subscribes userID to the new Comments of postID (or unsubscribes them)
+func SetSubscription(userID uint, postID uint, subscribed bool) {
+	var subscription models.Subscription
+	database.DB.Where("post_id = ? AND user_id = ?", postID, userID).Limit(1).Find(&subscription)
+	if subscription.ID == 0 {
+		// A concurrent Subscription to the same Post fails on the unique index, update it instead
+		err := database.DB.Create(&models.Subscription{PostID: postID, UserID: userID, Subscribed: subscribed}).Error
+		if database.IsDuplicateKeyError(err) {
+			database.DB.Model(&models.Subscription{}).Where("post_id = ? AND user_id = ?", postID, userID).UpdateColumn("subscribed", subscribed)
+		}
+	} else if subscription.Subscribed != subscribed {
+		database.DB.Model(&subscription).UpdateColumn("subscribed", subscribed)
+	}
+}
+
+// Subscribes userID to postID unless they have subscribed or unsubscribed before
+func AutoSubscribe(userID uint, postID uint) {
+	database.DB.Where("post_id = ? AND user_id = ?", postID, userID).FirstOrCreate(&models.Subscription{PostID: postID, UserID: userID, Subscribed: true})
+}
+
+func IsSubscribed(userID uint, postID uint) bool {
+	var subscription models.Subscription
+	database.DB.Where("post_id = ? AND user_id = ?", postID, userID).Limit(1).Find(&subscription)
+	return subscription.ID != 0 && subscription.Subscribed
+}
+
+// Notifies the subscribers of a Post of a new Comment by actor.
+// Users in skipUserIDs (e.g. those already notified of a mention in the Comment) are not notified again.
+func NotifySubscribers(actor *models.User, postID uint, commentID uint, anonymous bool, skipUserIDs []uint) {
+	var userIDs []uint
+	dbContext := database.DB.Model(&models.Subscription{}).Where("post_id = ? AND subscribed = ?", postID, true)
+	if len(skipUserIDs) > 0 {
+		dbContext = dbContext.Where("user_id NOT IN ?", skipUserIDs)
+	}
+	dbContext.Pluck("user_id", &userIDs)
+
+	for _, userID := range userIDs {
+		Notify(userID, actor, config.NOTIFICATION_COMMENT, postID, &commentID, anonymous)
+	}
+}
+
+/* -------------------------------------------------------------------------- */
+/*                                Notifications                               */
+/* -------------------------------------------------------------------------- */
+// Creates a Notification of notificationType for userID.
+// Users are never notified of their own actions or of the actions of Users they blocked, muted or are blocked by.
+func Notify(userID uint, actor *models.User, notificationType string, postID uint, commentID *uint, anonymous bool) {
+	if userID == actor.ID || auth.IsBlocked(userID, actor.ID) || auth.IsMuted(userID, actor.ID) {
+		return
+	}
+
+	database.DB.Create(&models.Notification{
+		UserID:    userID,
+		ActorID:   actor.ID,
+		Anonymous: anonymous,
+		Type:      notificationType,
+		PostID:    postID,
+		CommentID: commentID,
+	})
+}
+
+// Notifies Users that they were mentioned by actor in a Post or Comment
+func NotifyMentions(actor *models.User, userIDs []uint, postID uint, commentID *uint, anonymous bool) {
+	for _, userID := range userIDs {
+		Notify(userID, actor, config.NOTIFICATION_MENTION, postID, commentID, anonymous)
+	}
+}
+
+type NotificationResponse struct {
+	ID        uint   `json:"id" binding:"required"`
+	Type      string `json:"type" binding:"required"`
+	ActorID   uint   `json:"actorId" binding:"required"`
+	ActorName string `json:"actorName" binding:"required"`
+	Anonymous bool   `json:"anonymous" binding:"required"`
+	PostID    uint   `json:"postId"`
+	CommentID uint   `json:"commentId"`
+	Read      bool   `json:"read" binding:"required"`
+	CreatedAt int64  `json:"createdAt" binding:"required"`
+}
+
+// Convert a Notification Model into a JSON format.
+// Actors of anonymous Posts and Comments are shown by their pseudonym.
+func CreateNotificationResponse(notification *models.Notification) NotificationResponse {
+	actorID, actorName := notification.ActorID, notification.Actor.Username
+	if notification.Anonymous {
+		actorID, actorName = 0, utils.Pseudonym(notification.PostID, notification.ActorID)
+	}
+
+	var commentID uint
+	if notification.CommentID != nil {
+		commentID = *notification.CommentID
+	}
+
+	return NotificationResponse{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		ActorID:   actorID,
+		ActorName: actorName,
+		Anonymous: notification.Anonymous,
+		PostID:    notification.PostID,
+		CommentID: commentID,
+		Read:      notification.ReadAt != nil,
+		CreatedAt: notification.CreatedAt.Unix(),
+	}
+}
+
+type GetNotificationsResponse struct {
+	Notifications      []NotificationResponse `json:"notifications" binding:"required"`
+	NotificationsCount int64                  `json:"notificationsCount" binding:"required"`
+	UnreadCount        int64                  `json:"unreadCount" binding:"required"`
+}
+
+type ReadNotificationsResponse struct {
+	UnreadCount int64 `json:"unreadCount" binding:"required"`
+}
+
+// Fetches a page of Notifications of user (newest first)
+func GetNotificationsFromDB(user *models.User, perPage uint, pageNumber uint) GetNotificationsResponse {
+	var notifications []models.Notification
+
+	// Limit PerPage to config.MAX_PER_PAGE
+	clampedPerPage := int64(math.Min(config.MAX_PER_PAGE, float64(perPage)))
+	offsetNotificationsCount := int64(pageNumber-1) * clampedPerPage
+
+	// Get total and unread count for Notifications
+	var totalNotificationsCount, unreadCount int64
+	database.DB.Model(&models.Notification{}).Where("user_id = ?", user.ID).Count(&totalNotificationsCount)
+	database.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", user.ID).Count(&unreadCount)
+
+	notificationsResponse := []NotificationResponse{}
+
+	// If we are request beyond the bounds of total count, return nothing
+	if (offsetNotificationsCount < 0) || (offsetNotificationsCount > totalNotificationsCount) {
+		return GetNotificationsResponse{Notifications: notificationsResponse, NotificationsCount: 0, UnreadCount: unreadCount}
+	}
+
+	database.DB.Preload("Actor").Where("user_id = ?", user.ID).Order("created_at DESC, id DESC").Limit(int(clampedPerPage)).Offset(int(offsetNotificationsCount)).Find(&notifications)
+	for _, notification := range notifications {
+		notificationsResponse = append(notificationsResponse, CreateNotificationResponse(&notification))
+	}
+
+	return GetNotificationsResponse{
+		Notifications:      notificationsResponse,
+		NotificationsCount: totalNotificationsCount,
+		UnreadCount:        unreadCount,
+	}
+}
@@ -0,0 +1,8 @@
+package notifications
+
+import "github.com/gin-gonic/gin"
+
+func RegisterRoutes(r *gin.Engine) {
+	r.GET("notifications/get/:perPage/:pageNumber", GetNotifications)
+	r.POST("notifications/read", ReadNotifications)
+}
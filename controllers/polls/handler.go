@@ -0,0 +1,181 @@
+package polls
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/database"
+	"github.com/mfjkri/OneNUS-Backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errAlreadyVoted = errors.New("You have already voted on this poll.")
+var errNotVoted = errors.New("You have not voted on this poll.")
+
+/* -------------------------------------------------------------------------- */
+/*                        VotePoll | route: /polls/vote                       */
+/* -------------------------------------------------------------------------- */
+type VotePollRequest struct {
+	PostID    uint   `json:"postId" binding:"required"`
+	OptionIDs []uint `json:"optionIds" binding:"required"`
+}
+
+func VotePoll(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json VotePollRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Poll from PostID
+	poll, found := findPollFromPostID(c, &user, json.PostID)
+	if found == false {
+		return
+	}
+
+	// Check that Poll is still open
+	if poll.IsClosed() {
+		c.JSON(http.StatusForbidden, gin.H{"message": "This poll has closed."})
+		return
+	}
+
+	// Check number of Options voted for
+	if len(json.OptionIDs) == 0 || (!poll.MultipleChoice && len(json.OptionIDs) > 1) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Invalid number of options selected."})
+		return
+	}
+
+	// Check that every Option belongs to Poll (and is only chosen once)
+	var validOptionsCount int64
+	database.DB.Table("poll_options").Where("poll_id = ? AND id IN ?", poll.ID, json.OptionIDs).Count(&validOptionsCount)
+	if validOptionsCount != int64(len(json.OptionIDs)) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Invalid poll option."})
+		return
+	}
+
+	// Record votes and update tallies
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock Poll so that concurrent votes by the same User are serialized
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Poll{}, poll.ID)
+
+		var existingVotesCount int64
+		tx.Table("poll_votes").Where("poll_id = ? AND user_id = ?", poll.ID, user.ID).Count(&existingVotesCount)
+		if existingVotesCount > 0 {
+			return errAlreadyVoted
+		}
+
+		for _, optionID := range json.OptionIDs {
+			vote := models.PollVote{
+				PollID:       poll.ID,
+				PollOptionID: optionID,
+				UserID:       user.ID,
+			}
+			if err := tx.Create(&vote).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Model(&models.PollOption{}).Where("id = ?", optionID).UpdateColumn("votes_count", gorm.Expr("votes_count + ?", 1)).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.Poll{}).Where("id = ?", poll.ID).UpdateColumn("voters_count", gorm.Expr("voters_count + ?", 1)).Error
+	})
+
+	if err == errAlreadyVoted {
+		c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to vote. Try again later."})
+		return
+	}
+
+	fmt.Printf("%s has voted on a poll.\n\tPost ID: %d\n", user.Username, poll.PostID)
+
+	// Return updated Poll
+	database.DB.Preload("Options", orderOptions).First(&poll, poll.ID)
+	c.JSON(http.StatusAccepted, CreatePollResponse(&poll, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                  UnvotePoll | route: /polls/unvote/:postId                 */
+/* -------------------------------------------------------------------------- */
+type UnvotePollRequest struct {
+	PostID uint `uri:"postId" binding:"required"`
+}
+
+func UnvotePoll(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json UnvotePollRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Poll from PostID
+	poll, found := findPollFromPostID(c, &user, json.PostID)
+	if found == false {
+		return
+	}
+
+	// Votes can no longer be changed after the Poll closes
+	if poll.IsClosed() {
+		c.JSON(http.StatusForbidden, gin.H{"message": "This poll has closed."})
+		return
+	}
+
+	// Remove votes and update tallies
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock Poll so that concurrent votes by the same User are serialized
+		tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Poll{}, poll.ID)
+
+		var votes []models.PollVote
+		tx.Where("poll_id = ? AND user_id = ?", poll.ID, user.ID).Find(&votes)
+		if len(votes) == 0 {
+			return errNotVoted
+		}
+
+		for _, vote := range votes {
+			if err := tx.Delete(&vote).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Model(&models.PollOption{}).Where("id = ?", vote.PollOptionID).UpdateColumn("votes_count", gorm.Expr("votes_count - ?", 1)).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.Poll{}).Where("id = ?", poll.ID).UpdateColumn("voters_count", gorm.Expr("voters_count - ?", 1)).Error
+	})
+
+	if err == errNotVoted {
+		c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to remove vote. Try again later."})
+		return
+	}
+
+	fmt.Printf("%s has removed their vote on a poll.\n\tPost ID: %d\n", user.Username, poll.PostID)
+
+	// Return updated Poll
+	database.DB.Preload("Options", orderOptions).First(&poll, poll.ID)
+	c.JSON(http.StatusAccepted, CreatePollResponse(&poll, &user))
+}
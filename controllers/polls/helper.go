@@ -0,0 +1,195 @@
+package polls
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mfjkri/OneNUS-Backend/config"
+	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/database"
+	"github.com/mfjkri/OneNUS-Backend/models"
+	"github.com/mfjkri/OneNUS-Backend/utils"
+	"gorm.io/gorm"
+)
+
+// Poll attached to a new Post (see posts.CreatePostRequest)
+type CreatePollRequest struct {
+	Options        []string `json:"options" binding:"required"`
+	MultipleChoice bool     `json:"multipleChoice"`
+	HideResults    bool     `json:"hideResults"`
+	// Optional: unix seconds, required if HideResults is set
+	ClosesAt int64 `json:"closesAt"`
+}
+
+// Validates a CreatePollRequest and builds the Poll to be created along with its Post
+func NewPoll(c *gin.Context, json *CreatePollRequest) (*models.Poll, bool) {
+	// Check number of Options
+	if len(json.Options) < config.MIN_POLL_OPTIONS || len(json.Options) > config.MAX_POLL_OPTIONS {
+		c.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("Polls must have between %d and %d options.", config.MIN_POLL_OPTIONS, config.MAX_POLL_OPTIONS)})
+		return nil, false
+	}
+
+	// Check that each Option is valid and unique
+	var options []models.PollOption
+	seen := map[string]bool{}
+	for index, optionText := range json.Options {
+		text, err := utils.ValidateLine("Poll option", optionText, config.MAX_POLL_OPTION_CHAR)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+			return nil, false
+		}
+
+		if seen[text] {
+			c.JSON(http.StatusForbidden, gin.H{"message": "Poll options must be unique."})
+			return nil, false
+		}
+		seen[text] = true
+
+		options = append(options, models.PollOption{
+			Text:     text,
+			Position: uint(index),
+		})
+	}
+
+	// Check that the close time (if any) is in the future
+	var closesAt *time.Time
+	if json.ClosesAt != 0 {
+		closeTime := time.Unix(json.ClosesAt, 0)
+		if !closeTime.After(time.Now()) {
+			c.JSON(http.StatusForbidden, gin.H{"message": "Poll close time must be in the future."})
+			return nil, false
+		}
+		closesAt = &closeTime
+	}
+
+	// Results hidden until closed requires the Poll to close
+	if json.HideResults && closesAt == nil {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Polls with hidden results must have a close time."})
+		return nil, false
+	}
+
+	return &models.Poll{
+		MultipleChoice: json.MultipleChoice,
+		HideResults:    json.HideResults,
+		ClosesAt:       closesAt,
+		Options:        options,
+	}, true
+}
+
+func orderOptions(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC")
+}
+
+// Preloads the Poll (and its Options in order) of Posts
+func PreloadPoll(db *gorm.DB) *gorm.DB {
+	return db.Preload("Poll.Options", orderOptions)
+}
+
+// Finds the Poll of a published Post
+func findPollFromPostID(c *gin.Context, user *models.User, postID uint) (models.Poll, bool) {
+	var poll models.Poll
+	var post models.Post
+	database.DB.Table("polls").
+		Joins("JOIN posts ON posts.id = polls.post_id").
+		Where("polls.post_id = ? AND posts.status = ? AND posts.deleted_at IS NULL", postID, config.POST_STATUS_PUBLISHED).
+		Select("polls.*").
+		First(&poll)
+	if poll.ID != 0 {
+		database.DB.First(&post, poll.PostID)
+	}
+	if poll.ID == 0 || !auth.CanSeePost(user, &post) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Poll not found."})
+		return poll, false
+	}
+	return poll, true
+}
+
+type PollOptionResponse struct {
+	ID         uint   `json:"id" binding:"required"`
+	Text       string `json:"text" binding:"required"`
+	VotesCount uint   `json:"votesCount" binding:"required"`
+}
+
+type PollResponse struct {
+	ID             uint                 `json:"id" binding:"required"`
+	MultipleChoice bool                 `json:"multipleChoice" binding:"required"`
+	HideResults    bool                 `json:"hideResults" binding:"required"`
+	ClosesAt       int64                `json:"closesAt"`
+	Closed         bool                 `json:"closed" binding:"required"`
+	ResultsHidden  bool                 `json:"resultsHidden" binding:"required"`
+	VotersCount    uint                 `json:"votersCount" binding:"required"`
+	Options        []PollOptionResponse `json:"options" binding:"required"`
+	MyVote         []uint               `json:"myVote" binding:"required"`
+}
+
+// Fetches the options user voted for in each of the given Polls, keyed by PollID
+func FindMyVotes(pollIDs []uint, user *models.User) map[uint][]uint {
+	myVotes := map[uint][]uint{}
+	if len(pollIDs) == 0 {
+		return myVotes
+	}
+
+	var votes []models.PollVote
+	database.DB.Where("poll_id IN ? AND user_id = ?", pollIDs, user.ID).Order("poll_option_id ASC").Find(&votes)
+	for _, vote := range votes {
+		myVotes[vote.PollID] = append(myVotes[vote.PollID], vote.PollOptionID)
+	}
+	return myVotes
+}
+
+// Convert a Poll Model into a JSON format as seen by user.
+// Tallies are zeroed while results are hidden.
+func CreatePollResponse(poll *models.Poll, user *models.User) *PollResponse {
+	if poll == nil {
+		return nil
+	}
+	return CreatePollResponseFromVotes(poll, FindMyVotes([]uint{poll.ID}, user))
+}
+
+// Same as CreatePollResponse but uses votes prefetched with FindMyVotes,
+// so listings only need a single query for every Poll on the page.
+func CreatePollResponseFromVotes(poll *models.Poll, myVotes map[uint][]uint) *PollResponse {
+	if poll == nil {
+		return nil
+	}
+
+	var closesAt int64
+	if poll.ClosesAt != nil {
+		closesAt = poll.ClosesAt.Unix()
+	}
+	closed := poll.IsClosed()
+	resultsHidden := poll.HideResults && !closed
+
+	optionsResponse := []PollOptionResponse{}
+	for _, option := range poll.Options {
+		votesCount := option.VotesCount
+		if resultsHidden {
+			votesCount = 0
+		}
+
+		optionsResponse = append(optionsResponse, PollOptionResponse{
+			ID:         option.ID,
+			Text:       option.Text,
+			VotesCount: votesCount,
+		})
+	}
+
+	myVote := myVotes[poll.ID]
+	if myVote == nil {
+		myVote = []uint{}
+	}
+
+	return &PollResponse{
+		ID:             poll.ID,
+		MultipleChoice: poll.MultipleChoice,
+		HideResults:    poll.HideResults,
+		ClosesAt:       closesAt,
+		Closed:         closed,
+		ResultsHidden:  resultsHidden,
+		VotersCount:    poll.VotersCount,
+		Options:        optionsResponse,
+		MyVote:         myVote,
+	}
+}
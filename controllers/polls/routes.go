@@ -0,0 +1,8 @@
+package polls
+
+import "github.com/gin-gonic/gin"
+
+func RegisterRoutes(r *gin.Engine) {
+	r.POST("polls/vote", VotePoll)
+	r.DELETE("polls/unvote/:postId", UnvotePoll)
+}
@@ -3,15 +3,17 @@ package posts
 import (
 	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mfjkri/OneNUS-Backend/config"
 	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/controllers/notifications"
+	"github.com/mfjkri/OneNUS-Backend/controllers/polls"
 	"github.com/mfjkri/OneNUS-Backend/database"
 	"github.com/mfjkri/OneNUS-Backend/models"
 	"github.com/mfjkri/OneNUS-Backend/utils"
+	"gorm.io/gorm"
 )
 
 /* -------------------------------------------------------------------------- */
@@ -29,7 +31,7 @@ type GetPostsRequest struct {
 
 func GetPosts(c *gin.Context) {
 	// Check that RequestUser is authenticated
-	_, found := auth.VerifyAuth(c)
+	user, found := auth.VerifyAuth(c)
 	if found == false {
 		return
 	}
@@ -41,7 +43,8 @@ func GetPosts(c *gin.Context) {
 		return
 	}
 
-	dbContext := database.DB.Table("posts")
+	// Published Posts visible to RequestUser
+	dbContext := ListedPostsContext(&user)
 
 	// Filter database by UserID (if any)
 	if json.FilterUserID != 0 {
@@ -51,10 +54,15 @@ func GetPosts(c *gin.Context) {
 		} else {
 			dbContext = dbContext.Where("user_id = ?", targetUser.ID)
 		}
+
+		// Anonymous Posts are left out of profile listings
+		if !auth.CanSeeAnonymousAuthor(&user, targetUser.ID) {
+			dbContext = dbContext.Where("anonymous = ?", false)
+		}
 	}
 
 	// Filter database by FilterTag (if any)
-	if verifyTag(json.FilterTag) {
+	if VerifyTag(json.FilterTag) {
 		dbContext = dbContext.Where("tag = ?", json.FilterTag)
 	}
 
@@ -62,7 +70,67 @@ func GetPosts(c *gin.Context) {
 	posts, totalPostsCount := GetPostsFromContext(dbContext, json.PerPage, json.PageNumber, json.SortOption, json.SortOrder)
 
 	// Return fetched posts
-	c.JSON(http.StatusAccepted, CreatePostsResponse(&posts, totalPostsCount))
+	c.JSON(http.StatusAccepted, CreatePostsResponse(&posts, totalPostsCount, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                           ListPosts | route: ...                           */
+/* -------------------------------------------------------------------------- */
+// route: /posts/list?perPage=&page=&sort=&order=&authors=&tags=&from=&to=&minStars=&minComments=&unanswered=&text=
+// All parameters are optional, see docs/project-details.md for their defaults.
+type ListPostsRequest struct {
+	PerPage    uint   `form:"perPage"`
+	PageNumber uint   `form:"page"`
+	SortOption string `form:"sort"`
+	SortOrder  string `form:"order"`
+
+	// Comma separated UserIDs and tags
+	Authors string `form:"authors"`
+	Tags    string `form:"tags"`
+
+	// Unix seconds, inclusive
+	From int64 `form:"from"`
+	To   int64 `form:"to"`
+
+	MinStars    uint   `form:"minStars"`
+	MinComments uint   `form:"minComments"`
+	Unanswered  bool   `form:"unanswered"`
+	Text        string `form:"text"`
+}
+
+func ListPosts(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json ListPostsRequest
+	if err := c.ShouldBindQuery(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Apply defaults
+	if json.PerPage == 0 {
+		json.PerPage = config.DEFAULT_PER_PAGE
+	}
+	if json.PageNumber == 0 {
+		json.PageNumber = 1
+	}
+
+	// Filter published Posts visible to RequestUser
+	dbContext, valid := filterPosts(c, ListedPostsContext(&user), &json, &user)
+	if valid == false {
+		return
+	}
+
+	// Fetch posts
+	posts, totalPostsCount := GetPostsFromContext(dbContext, json.PerPage, json.PageNumber, json.SortOption, json.SortOrder)
+
+	// Return fetched posts
+	c.JSON(http.StatusAccepted, CreatePostsResponse(&posts, totalPostsCount, &user))
 }
 
 /* -------------------------------------------------------------------------- */
@@ -74,7 +142,7 @@ type GetPostByIDRequest struct {
 
 func GetPostByID(c *gin.Context) {
 	// Check that RequestUser is authenticated
-	_, found := auth.VerifyAuth(c)
+	user, found := auth.VerifyAuth(c)
 	if found == false {
 		return
 	}
@@ -88,14 +156,35 @@ func GetPostByID(c *gin.Context) {
 
 	// Find Post from PostID
 	var post models.Post
-	database.DB.First(&post, json.PostID)
+	database.DB.Scopes(preloadPost).First(&post, json.PostID)
 	if post.ID == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Post not found."})
 		return
 	}
 
+	// Unpublished Posts are only visible to their author
+	if post.Status != config.POST_STATUS_PUBLISHED && post.UserID != user.ID {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Post not found."})
+		return
+	}
+
+	// Posts hidden by reports are only visible to their author and moderators
+	if post.Hidden && !auth.CanSeeHiddenContent(&user, post.UserID) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Post not found."})
+		return
+	}
+
+	// Posts of blocked Users are not visible (anonymous Posts are, so that blocking does not reveal their author)
+	if !post.Anonymous && auth.IsBlocked(user.ID, post.UserID) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Post not found."})
+		return
+	}
+
+	// Count view of Post (buffered, saved by FlushPostViews)
+	RecordPostView(&post, &user)
+
 	// Return fetched Post
-	c.JSON(http.StatusAccepted, CreatePostResponse(&post))
+	c.JSON(http.StatusAccepted, CreatePostResponse(&post, &user))
 }
 
 /* -------------------------------------------------------------------------- */
@@ -105,6 +194,16 @@ type CreatePostRequest struct {
 	Title string `json:"title" binding:"required"`
 	Tag   string `json:"tag" binding:"required"`
 	Text  string `json:"text" binding:"required"`
+
+	// Optional: "draft" saves the Post privately, "scheduled" publishes it at PublishAt
+	Status    string `json:"status"`
+	PublishAt int64  `json:"publishAt"`
+
+	// Optional: Poll attached to the Post
+	Poll *polls.CreatePollRequest `json:"poll"`
+
+	// Optional: hides the author behind a pseudonym (only for config.ANONYMOUS_TAGS)
+	Anonymous bool `json:"anonymous"`
 }
 
 func CreatePost(c *gin.Context) {
@@ -121,56 +220,100 @@ func CreatePost(c *gin.Context) {
 		return
 	}
 
+	// Check that the Status (and PublishAt if scheduled) provided is valid
+	status, publishAt, validStatus := verifyStatus(json.Status, json.PublishAt)
+	if validStatus == false {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Invalid status or publish time for post."})
+		return
+	}
+
 	// Prevent frequent CreatePosts by User
+	// Drafts and scheduled Posts are only subjected to the cooldown when they get published
 	timeNow, canCreatePost := utils.CheckTimeIsAfter(user.LastPostAt, config.USER_POST_COOLDOWN)
-	if canCreatePost == false {
+	if status == config.POST_STATUS_PUBLISHED && canCreatePost == false {
 		cdLeft := utils.GetCooldownLeft(user.LastPostAt, config.USER_POST_COOLDOWN, timeNow)
 		c.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("Creating posts too frequently. Please try again in %ds", int(cdLeft.Seconds()))})
 		return
 	}
 
-	// Check that Title and Text does not contain illegal characters
-	if !(utils.ContainsValidCharactersOnly(json.Title) && utils.ContainsValidCharactersOnly(json.Text)) {
-		c.JSON(http.StatusForbidden, gin.H{"message": "Title or Body contains illegal characters."})
+	// Check that Title and Text are valid
+	title, text, validContent := validatePostContent(c, json.Title, json.Text)
+	if validContent == false {
 		return
 	}
 
 	// Check that the Tag provided is valid
-	validTag := verifyTag(json.Tag)
+	validTag := VerifyTag(json.Tag)
 	if validTag == false {
 		c.JSON(http.StatusForbidden, gin.H{"message": "Unknown tag for post."})
 		return
 	}
 
+	// Check that the Tag allows anonymous Posts
+	if json.Anonymous && !config.ANONYMOUS_TAGS[json.Tag] {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Anonymous posting is not allowed for this tag."})
+		return
+	}
+
+	// Check that the Poll (if any) is valid
+	var poll *models.Poll
+	if json.Poll != nil {
+		newPoll, validPoll := polls.NewPoll(c, json.Poll)
+		if validPoll == false {
+			return
+		}
+		poll = newPoll
+	}
+
 	// Try to create new Post
 	post := models.Post{
-		Title:         utils.TrimString(strings.TrimSpace(json.Title), config.MAX_POST_TITLE_CHAR),
+		Title:         title,
 		Tag:           json.Tag,
-		Text:          utils.TrimString(strings.TrimSpace(json.Text), config.MAX_POST_TEXT_CHAR),
+		Text:          text,
+		Status:        status,
+		PublishAt:     publishAt,
+		Poll:          poll,
 		Author:        user.Username,
 		User:          user,
+		Anonymous:     json.Anonymous,
 		CommentsCount: 0,
 		CommentedAt:   time.Unix(0, 0),
 		StarsCount:    0,
 	}
-	new_entry := database.DB.Create(&post)
+	mentions := renderPostText(&post, &user)
+
+	// Create Post and update PostsCount and LastPostAt for User together (drafts are not counted)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&post).Error; err != nil {
+			return err
+		}
+		if status == config.POST_STATUS_PUBLISHED {
+			return incrementPostsCount(tx, &user, timeNow)
+		}
+		return nil
+	})
 
 	// Failed to create entry
-	if new_entry.Error != nil {
+	if err != nil {
 		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to create post. Try again later."})
 		return
 	}
 
 	// Successfully created a new Post
+	savePostMentions(&post, &user, mentions)
 
-	// Update PostsCount and LastPostAt for User
-	user.PostsCount += 1
-	user.LastPostAt = timeNow
-	database.DB.Save(&user)
+	// Author is notified of new Comments on their Post
+	if config.AUTO_SUBSCRIBE_AUTHORS {
+		notifications.AutoSubscribe(user.ID, post.ID)
+	}
 
-	fmt.Printf("%s has created a post.\n\tPost title: %s\n\tPost text: %s\n", user.Username, post.Title, post.Text)
+	if status == config.POST_STATUS_PUBLISHED {
+		fmt.Printf("%s has created a post.\n\tPost title: %s\n\tPost text: %s\n", user.Username, post.Title, post.Text)
+	} else {
+		fmt.Printf("%s has saved a %s post.\n\tPost title: %s\n", user.Username, post.Status, post.Title)
+	}
 
-	c.JSON(http.StatusAccepted, CreatePostResponse(&post))
+	c.JSON(http.StatusAccepted, CreatePostResponse(&post, &user))
 }
 
 /* -------------------------------------------------------------------------- */
@@ -205,7 +348,7 @@ func UpdatePostText(c *gin.Context) {
 
 	// Find Post from PostID
 	var post models.Post
-	database.DB.First(&post, json.PostID)
+	database.DB.Scopes(preloadPost).First(&post, json.PostID)
 	if post.ID == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Post not found."})
 		return
@@ -217,16 +360,26 @@ func UpdatePostText(c *gin.Context) {
 		return
 	}
 
-	// Replace Post text and update User LastPostAt
-	post.Text = utils.TrimString(strings.TrimSpace(json.Text), config.MAX_POST_TEXT_CHAR)
+	// Check that Text is valid
+	text, err := utils.ValidateText("Body", json.Text, config.MAX_POST_TEXT_CHAR)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Replace Post text and update User LastPostAt.
+	// Only the changed columns are written so that concurrent counter updates are not overwritten.
+	post.Text = text
+	mentions := renderPostText(&post, &user)
 	user.LastPostAt = timeNow
-	database.DB.Save(&post)
-	database.DB.Save(&user)
+	database.DB.Model(&post).Updates(map[string]interface{}{"text": post.Text, "text_html": post.TextHTML})
+	database.DB.Model(&user).UpdateColumn("last_post_at", user.LastPostAt)
+	savePostMentions(&post, &user, mentions)
 
 	fmt.Printf("%s has updated a post.\n\tPost title: %s\n\tNew text: %s\n", user.Username, post.Title, post.Text)
 
 	// Return new Post data
-	c.JSON(http.StatusAccepted, CreatePostResponse(&post))
+	c.JSON(http.StatusAccepted, CreatePostResponse(&post, &user))
 }
 
 /* -------------------------------------------------------------------------- */
@@ -264,14 +417,739 @@ func DeletePost(c *gin.Context) {
 		return
 	}
 
-	database.DB.Delete(&post)
-
-	// Update PostsCount for User
-	// user.PostsCount -= 1
-	// database.DB.Save(&user)
+	// Soft delete Post, it can be restored until it is purged
+	RemovePost(&post, &user)
 
 	fmt.Printf("%s has deleted a post.\n\tPost title: %s\n", user.Username, post.Title)
 
 	// Return new Post data
-	c.JSON(http.StatusAccepted, CreatePostResponse(&post))
+	c.JSON(http.StatusAccepted, CreatePostResponse(&post, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                        LockPost | route: /posts/lock                       */
+/* -------------------------------------------------------------------------- */
+type LockPostRequest struct {
+	PostID uint   `json:"postId" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+func LockPost(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json LockPostRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Check User is a moderator
+	if !auth.IsModerator(&user) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You do not have valid permissions."})
+		return
+	}
+
+	// Check that Reason is valid
+	reason, err := utils.ValidateLine("Reason", json.Reason, config.MAX_LOCK_REASON_CHAR)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Post from PostID
+	var post models.Post
+	database.DB.Scopes(preloadPost).First(&post, json.PostID)
+	if post.ID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Post not found."})
+		return
+	}
+
+	// Lock Post without touching UpdatedAt
+	post.Locked = true
+	post.LockedReason = reason
+	post.LockedByID = user.ID
+	database.DB.Model(&post).UpdateColumns(map[string]interface{}{
+		"locked":        post.Locked,
+		"locked_reason": post.LockedReason,
+		"locked_by_id":  post.LockedByID,
+	})
+
+	fmt.Printf("%s has locked a post.\n\tPost title: %s\n\tReason: %s\n", user.Username, post.Title, post.LockedReason)
+
+	// Return locked Post data
+	c.JSON(http.StatusAccepted, CreatePostResponse(&post, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                      UnlockPost | route: /posts/unlock                     */
+/* -------------------------------------------------------------------------- */
+type UnlockPostRequest struct {
+	PostID uint `json:"postId" binding:"required"`
+}
+
+func UnlockPost(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json UnlockPostRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Check User is a moderator
+	if !auth.IsModerator(&user) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You do not have valid permissions."})
+		return
+	}
+
+	// Find Post from PostID
+	var post models.Post
+	database.DB.Scopes(preloadPost).First(&post, json.PostID)
+	if post.ID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Post not found."})
+		return
+	}
+
+	// Unlock Post without touching UpdatedAt
+	post.Locked = false
+	post.LockedReason = ""
+	post.LockedByID = 0
+	database.DB.Model(&post).UpdateColumns(map[string]interface{}{
+		"locked":        post.Locked,
+		"locked_reason": post.LockedReason,
+		"locked_by_id":  post.LockedByID,
+	})
+
+	fmt.Printf("%s has unlocked a post.\n\tPost title: %s\n", user.Username, post.Title)
+
+	// Return unlocked Post data
+	c.JSON(http.StatusAccepted, CreatePostResponse(&post, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                           GetDrafts | route: ...                           */
+/* -------------------------------------------------------------------------- */
+// route: /posts/drafts/:perPage/:pageNumber
+type GetDraftsRequest struct {
+	PerPage    uint `uri:"perPage" binding:"required"`
+	PageNumber uint `uri:"pageNumber" binding:"required"`
+}
+
+func GetDrafts(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetDraftsRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Drafts and scheduled Posts of RequestUser
+	dbContext := database.DB.Model(&models.Post{}).Where("user_id = ? AND status <> ?", user.ID, config.POST_STATUS_PUBLISHED)
+
+	// Fetch drafts
+	posts, totalPostsCount := GetPostsFromContext(dbContext, json.PerPage, json.PageNumber, "", "")
+
+	// Return fetched drafts
+	c.JSON(http.StatusAccepted, CreatePostsResponse(&posts, totalPostsCount, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                   UpdateDraft | route: /posts/updatedraft                  */
+/* -------------------------------------------------------------------------- */
+type UpdateDraftRequest struct {
+	PostID uint   `json:"postId" binding:"required"`
+	Title  string `json:"title" binding:"required"`
+	Tag    string `json:"tag" binding:"required"`
+	Text   string `json:"text" binding:"required"`
+}
+
+func UpdateDraft(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json UpdateDraftRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find unpublished Post from PostID
+	post, found := findDraft(c, &user, json.PostID)
+	if found == false {
+		return
+	}
+
+	// Check that Title and Text are valid
+	title, text, validContent := validatePostContent(c, json.Title, json.Text)
+	if validContent == false {
+		return
+	}
+
+	// Check that the Tag provided is valid
+	validTag := VerifyTag(json.Tag)
+	if validTag == false {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Unknown tag for post."})
+		return
+	}
+
+	// Check that the Tag allows anonymous Posts
+	if post.Anonymous && !config.ANONYMOUS_TAGS[json.Tag] {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Anonymous posting is not allowed for this tag."})
+		return
+	}
+
+	// Replace draft contents
+	post.Title = title
+	post.Tag = json.Tag
+	post.Text = text
+	mentions := renderPostText(&post, &user)
+	database.DB.Model(&post).Updates(map[string]interface{}{"title": post.Title, "tag": post.Tag, "text": post.Text, "text_html": post.TextHTML})
+	savePostMentions(&post, &user, mentions)
+
+	fmt.Printf("%s has updated a draft.\n\tPost title: %s\n", user.Username, post.Title)
+
+	// Return updated draft
+	c.JSON(http.StatusAccepted, CreatePostResponse(&post, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                     PublishPost | route: /posts/publish                    */
+/* -------------------------------------------------------------------------- */
+type PublishPostRequest struct {
+	PostID uint `json:"postId" binding:"required"`
+
+	// Optional: publishes immediately if not provided
+	PublishAt int64 `json:"publishAt"`
+}
+
+func PublishPost(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json PublishPostRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find unpublished Post from PostID
+	post, found := findDraft(c, &user, json.PostID)
+	if found == false {
+		return
+	}
+
+	// Schedule Post to be published by the background publisher
+	if json.PublishAt != 0 {
+		status, publishAt, validStatus := verifyStatus(config.POST_STATUS_SCHEDULED, json.PublishAt)
+		if validStatus == false {
+			c.JSON(http.StatusForbidden, gin.H{"message": "Publish time must be in the future."})
+			return
+		}
+
+		post.Status = status
+		post.PublishAt = publishAt
+		database.DB.Model(&post).Updates(map[string]interface{}{"status": post.Status, "publish_at": post.PublishAt})
+
+		fmt.Printf("%s has scheduled a post.\n\tPost title: %s\n\tPublish at: %s\n", user.Username, post.Title, post.PublishAt)
+
+		c.JSON(http.StatusAccepted, CreatePostResponse(&post, &user))
+		return
+	}
+
+	// Prevent frequent PublishPost by User
+	timeNow, canCreatePost := utils.CheckTimeIsAfter(user.LastPostAt, config.USER_POST_COOLDOWN)
+	if canCreatePost == false {
+		cdLeft := utils.GetCooldownLeft(user.LastPostAt, config.USER_POST_COOLDOWN, timeNow)
+		c.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("Creating posts too frequently. Please try again in %ds", int(cdLeft.Seconds()))})
+		return
+	}
+
+	// A Post published concurrently (e.g. by the scheduler) is returned as it is
+	if publishPost(&post, &user, timeNow) == false {
+		database.DB.Scopes(preloadPost).First(&post, post.ID)
+		c.JSON(http.StatusAccepted, CreatePostResponse(&post, &user))
+		return
+	}
+
+	fmt.Printf("%s has published a post.\n\tPost title: %s\n\tPost text: %s\n", user.Username, post.Title, post.Text)
+
+	// Return published Post
+	c.JSON(http.StatusAccepted, CreatePostResponse(&post, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                        StarPost | route: /posts/star                       */
+/* -------------------------------------------------------------------------- */
+type StarPostRequest struct {
+	PostID uint `json:"postId" binding:"required"`
+}
+
+func StarPost(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json StarPostRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Post from PostID
+	var post models.Post
+	database.DB.First(&post, json.PostID)
+	if post.ID == 0 || post.Status != config.POST_STATUS_PUBLISHED || !auth.CanSeePost(&user, &post) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Post not found."})
+		return
+	}
+
+	// Create Star and increment StarsCount together
+	var alreadyStarred bool
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var existingStar models.Star
+		tx.Where("post_id = ? AND user_id = ?", post.ID, user.ID).Limit(1).Find(&existingStar)
+		if existingStar.ID != 0 {
+			alreadyStarred = true
+			return nil
+		}
+
+		// A concurrent Star of the same Post fails on the unique index
+		star := models.Star{PostID: post.ID, UserID: user.ID}
+		if err := tx.Create(&star).Error; err != nil {
+			if database.IsDuplicateKeyError(err) {
+				alreadyStarred = true
+				return nil
+			}
+			return err
+		}
+		return tx.Model(&post).UpdateColumn("stars_count", gorm.Expr("stars_count + ?", 1)).Error
+	})
+
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to star post. Try again later."})
+		return
+	}
+	if alreadyStarred {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You have already starred this post."})
+		return
+	}
+
+	// Return updated Post
+	database.DB.Scopes(preloadPost).First(&post, post.ID)
+	c.JSON(http.StatusAccepted, CreatePostResponse(&post, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                  UnstarPost | route: /posts/unstar/:postId                 */
+/* -------------------------------------------------------------------------- */
+type UnstarPostRequest struct {
+	PostID uint `uri:"postId" binding:"required"`
+}
+
+func UnstarPost(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json UnstarPostRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Post from PostID
+	var post models.Post
+	database.DB.First(&post, json.PostID)
+	if post.ID == 0 || !auth.CanSeePost(&user, &post) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Post not found."})
+		return
+	}
+
+	// Delete Star and decrement StarsCount together
+	var notStarred bool
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("post_id = ? AND user_id = ?", post.ID, user.ID).Delete(&models.Star{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			notStarred = true
+			return nil
+		}
+		return tx.Model(&post).Where("stars_count > 0").UpdateColumn("stars_count", gorm.Expr("stars_count - ?", 1)).Error
+	})
+
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to unstar post. Try again later."})
+		return
+	}
+	if notStarred {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You have not starred this post."})
+		return
+	}
+
+	// Return updated Post
+	database.DB.Scopes(preloadPost).First(&post, post.ID)
+	c.JSON(http.StatusAccepted, CreatePostResponse(&post, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                        GetPostAnalytics | route: ...                       */
+/* -------------------------------------------------------------------------- */
+// route: /posts/analytics/:postId/:days
+type GetPostAnalyticsRequest struct {
+	PostID uint `uri:"postId" binding:"required"`
+	Days   int  `uri:"days" binding:"required"`
+}
+
+func GetPostAnalytics(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetPostAnalyticsRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Post from PostID
+	var post models.Post
+	database.DB.First(&post, json.PostID)
+	if post.ID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Post not found."})
+		return
+	}
+
+	// Analytics are only visible to the author of the Post
+	if post.UserID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You do not have valid permissions."})
+		return
+	}
+
+	// Clamp number of days
+	days := json.Days
+	if days < 1 {
+		days = 1
+	} else if days > config.MAX_POST_ANALYTICS_DAYS {
+		days = config.MAX_POST_ANALYTICS_DAYS
+	}
+
+	// Return Post analytics
+	c.JSON(http.StatusAccepted, CreatePostAnalyticsResponse(&post, days))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                            GetTrash | route: ...                           */
+/* -------------------------------------------------------------------------- */
+// route: /posts/trash/:perPage/:pageNumber
+type GetTrashRequest struct {
+	PerPage    uint `uri:"perPage" binding:"required"`
+	PageNumber uint `uri:"pageNumber" binding:"required"`
+}
+
+func GetTrash(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetTrashRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Posts deleted by RequestUser that have not been purged yet
+	dbContext := database.DB.Unscoped().Model(&models.Post{}).Where("user_id = ? AND deleted_by_id = ? AND deleted_at IS NOT NULL", user.ID, user.ID)
+
+	// Fetch deleted Posts
+	posts, totalPostsCount := GetPostsFromContext(dbContext, json.PerPage, json.PageNumber, "", "")
+
+	// Return fetched deleted Posts
+	c.JSON(http.StatusAccepted, CreatePostsResponse(&posts, totalPostsCount, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                     RestorePost | route: /posts/restore                    */
+/* -------------------------------------------------------------------------- */
+type RestorePostRequest struct {
+	PostID uint `json:"postId" binding:"required"`
+}
+
+func RestorePost(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json RestorePostRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find deleted Post from PostID
+	post, found := findDeletedPost(c, &user, json.PostID)
+	if found == false {
+		return
+	}
+
+	// Posts of deleted Users are restored together with the User
+	var author models.User
+	database.DB.First(&author, post.UserID)
+	if author.ID == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"message": "The author of this post has been deleted."})
+		return
+	}
+
+	// Restore Post
+	restorePost(&post)
+
+	fmt.Printf("%s has restored a post.\n\tPost title: %s\n", user.Username, post.Title)
+
+	// Return restored Post data
+	c.JSON(http.StatusAccepted, CreatePostResponse(&post, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                     FollowTag | route: /posts/followtag                    */
+/* -------------------------------------------------------------------------- */
+type FollowTagRequest struct {
+	Tag string `json:"tag" binding:"required"`
+}
+
+func FollowTag(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json FollowTagRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Check that Tag is valid
+	if !VerifyTag(json.Tag) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Invalid tag."})
+		return
+	}
+
+	// Create TagFollow (following a Tag twice is a no-op)
+	var existingTagFollow models.TagFollow
+	database.DB.Where("user_id = ? AND tag = ?", user.ID, json.Tag).Limit(1).Find(&existingTagFollow)
+	if existingTagFollow.ID == 0 {
+		database.DB.Create(&models.TagFollow{UserID: user.ID, Tag: json.Tag})
+	}
+
+	// Return followed Tags
+	c.JSON(http.StatusAccepted, FollowedTagsResponse{Tags: followedTags(&user)})
+}
+
+/* -------------------------------------------------------------------------- */
+/*                UnfollowTag | route: /posts/unfollowtag/:tag                */
+/* -------------------------------------------------------------------------- */
+type UnfollowTagRequest struct {
+	Tag string `uri:"tag" binding:"required"`
+}
+
+func UnfollowTag(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json UnfollowTagRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Delete TagFollow
+	database.DB.Where("user_id = ? AND tag = ?", user.ID, json.Tag).Delete(&models.TagFollow{})
+
+	// Return followed Tags
+	c.JSON(http.StatusAccepted, FollowedTagsResponse{Tags: followedTags(&user)})
+}
+
+/* -------------------------------------------------------------------------- */
+/*                GetFollowedTags | route: /posts/followedtags                */
+/* -------------------------------------------------------------------------- */
+func GetFollowedTags(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Return followed Tags
+	c.JSON(http.StatusAccepted, FollowedTagsResponse{Tags: followedTags(&user)})
+}
+
+/* -------------------------------------------------------------------------- */
+/*                        GetFollowingFeed | route: ...                       */
+/* -------------------------------------------------------------------------- */
+// route: /posts/following/:perPage/:pageNumber/:sortOption/:sortOrder
+type GetFollowingFeedRequest struct {
+	PerPage    uint   `uri:"perPage" binding:"required"`
+	PageNumber uint   `uri:"pageNumber" binding:"required"`
+	SortOption string `uri:"sortOption"`
+	SortOrder  string `uri:"sortOrder"`
+}
+
+func GetFollowingFeed(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetFollowingFeedRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Fetch Posts from followed Users and Tags
+	posts, totalPostsCount := GetPostsFromContext(followingFeedContext(&user), json.PerPage, json.PageNumber, json.SortOption, json.SortOrder)
+
+	// Return fetched posts
+	c.JSON(http.StatusAccepted, CreatePostsResponse(&posts, totalPostsCount, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                  SubscribeToPost | route: /posts/subscribe                 */
+/* -------------------------------------------------------------------------- */
+type SubscribeToPostRequest struct {
+	PostID uint `json:"postId" binding:"required"`
+}
+
+func SubscribeToPost(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json SubscribeToPostRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Post from PostID
+	var post models.Post
+	database.DB.First(&post, json.PostID)
+	if post.ID == 0 || post.Status != config.POST_STATUS_PUBLISHED || !auth.CanSeePost(&user, &post) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Post not found."})
+		return
+	}
+
+	notifications.SetSubscription(user.ID, post.ID, true)
+
+	c.JSON(http.StatusAccepted, SubscriptionResponse{PostID: post.ID, Subscribed: true})
+}
+
+/* -------------------------------------------------------------------------- */
+/*           UnsubscribeFromPost | route: /posts/unsubscribe/:postId          */
+/* -------------------------------------------------------------------------- */
+type UnsubscribeFromPostRequest struct {
+	PostID uint `uri:"postId" binding:"required"`
+}
+
+func UnsubscribeFromPost(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json UnsubscribeFromPostRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find Post from PostID
+	var post models.Post
+	database.DB.First(&post, json.PostID)
+	if post.ID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Post not found."})
+		return
+	}
+
+	// Unsubscribed Users are not auto-subscribed to the Post again
+	notifications.SetSubscription(user.ID, post.ID, false)
+
+	c.JSON(http.StatusAccepted, SubscriptionResponse{PostID: post.ID, Subscribed: false})
+}
+
+/* -------------------------------------------------------------------------- */
+/*            GetSubscription | route: /posts/subscription/:postId            */
+/* -------------------------------------------------------------------------- */
+type GetSubscriptionRequest struct {
+	PostID uint `uri:"postId" binding:"required"`
+}
+
+func GetSubscription(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetSubscriptionRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SubscriptionResponse{PostID: json.PostID, Subscribed: notifications.IsSubscribed(user.ID, json.PostID)})
 }
@@ -1,14 +1,28 @@
 package posts
 
 import (
+	"fmt"
 	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/mfjkri/OneNUS-Backend/config"
+	"github.com/mfjkri/OneNUS-Backend/controllers/attachments"
+	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/controllers/notifications"
+	"github.com/mfjkri/OneNUS-Backend/controllers/polls"
+	"github.com/mfjkri/OneNUS-Backend/database"
 	"github.com/mfjkri/OneNUS-Backend/models"
+	"github.com/mfjkri/OneNUS-Backend/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func verifyTag(tag string) (valid bool) {
+func VerifyTag(tag string) (valid bool) {
 	valid = false
 	for _, x := range models.ValidTags {
 		if x == tag {
@@ -18,34 +32,696 @@ func verifyTag(tag string) (valid bool) {
 	return
 }
 
+// Tags of Q&A Posts (see config.QA_TAGS), in the order of models.ValidTags
+func qaTags() []string {
+	var tags []string
+	for _, tag := range models.ValidTags {
+		if config.QA_TAGS[tag] {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// Normalizes and validates the Title and Text of a Post
+func validatePostContent(c *gin.Context, title string, text string) (string, string, bool) {
+	title, err := utils.ValidateLine("Title", title, config.MAX_POST_TITLE_CHAR)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		return title, text, false
+	}
+
+	text, err = utils.ValidateText("Body", text, config.MAX_POST_TEXT_CHAR)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		return title, text, false
+	}
+
+	return title, text, true
+}
+
+// Preloads the associations returned in a PostResponse
+func preloadPost(db *gorm.DB) *gorm.DB {
+	return polls.PreloadPoll(db.Preload("Attachments"))
+}
+
+// Renders Post text into sanitized HTML
+func renderPostText(post *models.Post, author *models.User) map[string]uint {
+	mentions := notifications.ResolveMentions(post.Text, author)
+	post.TextHTML = utils.RenderMarkdownWithMentions(post.Text, config.CODE_HIGHLIGHT_TAGS[post.Tag], mentions)
+	return mentions
+}
+
+// Saves the Mentions of a saved Post. Newly mentioned Users are notified once the Post is published.
+func savePostMentions(post *models.Post, author *models.User, mentions map[string]uint) {
+	newUserIDs := notifications.SaveMentions(post.ID, nil, mentions)
+	if post.Status == config.POST_STATUS_PUBLISHED {
+		notifications.NotifyMentions(author, newUserIDs, post.ID, nil, post.Anonymous)
+	}
+}
+
+// Resolves the requested Status of a new Post.
+// Scheduled Posts must be given a PublishAt (unix seconds) in the future.
+func verifyStatus(status string, publishAtUnix int64) (string, *time.Time, bool) {
+	switch status {
+	case "", config.POST_STATUS_PUBLISHED:
+		return config.POST_STATUS_PUBLISHED, nil, true
+	case config.POST_STATUS_DRAFT:
+		return config.POST_STATUS_DRAFT, nil, true
+	case config.POST_STATUS_SCHEDULED:
+		publishAt := time.Unix(publishAtUnix, 0)
+		if publishAt.After(time.Now()) {
+			return config.POST_STATUS_SCHEDULED, &publishAt, true
+		}
+	}
+	return "", nil, false
+}
+
+// Posts that can be listed for user.
+// Drafts and scheduled Posts are never listed and Posts hidden by reports are only listed for their author and moderators.
+// Posts of blocked and muted Users are left out.
+func ListedPostsContext(user *models.User) *gorm.DB {
+	dbContext := database.DB.Model(&models.Post{}).Where("status = ?", config.POST_STATUS_PUBLISHED)
+	if !auth.IsModerator(user) {
+		dbContext = dbContext.Where("hidden = ? OR user_id = ?", false, user.ID)
+	}
+	return auth.FilterBlockedContent(dbContext, user)
+}
+
+// Listed Posts by the Users that user follows or with the tags that user follows.
+// Anonymous Posts only show up through their tag, never through their author.
+func followingFeedContext(user *models.User) *gorm.DB {
+	followedUsers := database.DB.Model(&models.Follow{}).Select("user_id").Where("follower_id = ?", user.ID)
+	followedTags := database.DB.Model(&models.TagFollow{}).Select("tag").Where("user_id = ?", user.ID)
+	return ListedPostsContext(user).Where("(user_id IN (?) AND anonymous = ?) OR tag IN (?)", followedUsers, false, followedTags)
+}
+
+// Tags that user follows, in the order of models.ValidTags
+func followedTags(user *models.User) []string {
+	var tags []string
+	database.DB.Model(&models.TagFollow{}).Where("user_id = ?", user.ID).Pluck("tag", &tags)
+
+	followedTags := []string{}
+	for _, tag := range models.ValidTags {
+		for _, followedTag := range tags {
+			if tag == followedTag {
+				followedTags = append(followedTags, tag)
+			}
+		}
+	}
+	return followedTags
+}
+
+type FollowedTagsResponse struct {
+	Tags []string `json:"tags" binding:"required"`
+}
+
+type SubscriptionResponse struct {
+	PostID     uint `json:"postId" binding:"required"`
+	Subscribed bool `json:"subscribed" binding:"required"`
+}
+
+// Applies the filters of a ListPostsRequest to dbContext
+func filterPosts(c *gin.Context, dbContext *gorm.DB, json *ListPostsRequest, user *models.User) (*gorm.DB, bool) {
+	// Check sort option and order
+	if json.SortOption != "" && json.SortOption != "new" && json.SortOption != "recent" && json.SortOption != "hot" {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Unknown sort option."})
+		return dbContext, false
+	}
+	if json.SortOrder != "" && json.SortOrder != "ascending" && json.SortOrder != "descending" {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Unknown sort order."})
+		return dbContext, false
+	}
+
+	// Filter by authors (if any)
+	if json.Authors != "" {
+		var authorIDs []uint
+		for _, field := range strings.Split(json.Authors, ",") {
+			authorID, err := strconv.ParseUint(strings.TrimSpace(field), 10, 32)
+			if err != nil || authorID == 0 {
+				c.JSON(http.StatusForbidden, gin.H{"message": "Authors must be a comma separated list of user IDs."})
+				return dbContext, false
+			}
+			authorIDs = append(authorIDs, uint(authorID))
+		}
+
+		if len(authorIDs) > config.MAX_FILTER_AUTHORS {
+			c.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("Too many authors (max %d).", config.MAX_FILTER_AUTHORS)})
+			return dbContext, false
+		}
+		dbContext = dbContext.Where("user_id IN ?", authorIDs)
+
+		// Anonymous Posts are left out of author listings
+		if !auth.IsModerator(user) {
+			dbContext = dbContext.Where("anonymous = ? OR user_id = ?", false, user.ID)
+		}
+	}
+
+	// Filter by tags (if any)
+	if json.Tags != "" {
+		var tags []string
+		for _, field := range strings.Split(json.Tags, ",") {
+			tag := strings.TrimSpace(field)
+			if !VerifyTag(tag) {
+				c.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("Unknown tag: %s.", tag)})
+				return dbContext, false
+			}
+			tags = append(tags, tag)
+		}
+		dbContext = dbContext.Where("tag IN ?", tags)
+	}
+
+	// Filter by creation date (if any)
+	if json.From != 0 && json.To != 0 && json.From > json.To {
+		c.JSON(http.StatusForbidden, gin.H{"message": "From must be before to."})
+		return dbContext, false
+	}
+	if json.From != 0 {
+		dbContext = dbContext.Where("created_at >= ?", time.Unix(json.From, 0))
+	}
+	if json.To != 0 {
+		dbContext = dbContext.Where("created_at <= ?", time.Unix(json.To, 0))
+	}
+
+	// Filter by stars and comments
+	if json.MinStars != 0 {
+		dbContext = dbContext.Where("stars_count >= ?", json.MinStars)
+	}
+	if json.Unanswered && json.MinComments != 0 {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Unanswered cannot be combined with minComments."})
+		return dbContext, false
+	}
+	if json.Unanswered {
+		// Q&A Posts are answered once a Comment is accepted, other Posts once they have a Comment
+		if tags := qaTags(); len(tags) > 0 {
+			dbContext = dbContext.Where("(tag IN ? AND accepted_comment_id IS NULL) OR (tag NOT IN ? AND comments_count = ?)", tags, tags, 0)
+		} else {
+			dbContext = dbContext.Where("comments_count = ?", 0)
+		}
+	} else if json.MinComments != 0 {
+		dbContext = dbContext.Where("comments_count >= ?", json.MinComments)
+	}
+
+	// Filter by text in title or body (if any)
+	if json.Text != "" {
+		text, err := utils.ValidateLine("Search text", json.Text, config.MAX_SEARCH_TEXT_CHAR)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+			return dbContext, false
+		}
+		pattern := "%" + likeEscaper.Replace(text) + "%"
+		dbContext = dbContext.Where("title LIKE ? OR text LIKE ?", pattern, pattern)
+	}
+
+	return dbContext, true
+}
+
+// Escapes LIKE wildcards so that search text is matched literally
+var likeEscaper = strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+
+// Finds an unpublished Post that belongs to user
+func findDraft(c *gin.Context, user *models.User, postID uint) (models.Post, bool) {
+	var post models.Post
+	database.DB.Scopes(preloadPost).Where("status <> ?", config.POST_STATUS_PUBLISHED).First(&post, postID)
+	if post.ID == 0 || post.UserID != user.ID {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Draft not found."})
+		return post, false
+	}
+	return post, true
+}
+
+// Increments PostsCount and sets LastPostAt of user (as part of tx)
+func incrementPostsCount(tx *gorm.DB, user *models.User, timeNow time.Time) error {
+	return tx.Model(&models.User{}).Where("id = ?", user.ID).UpdateColumns(map[string]interface{}{
+		"posts_count":  gorm.Expr("posts_count + ?", 1),
+		"last_post_at": timeNow,
+	}).Error
+}
+
+// Publishes a draft or scheduled Post as if it was just created.
+// Returns false if the Post was already published concurrently.
+func publishPost(post *models.Post, user *models.User, timeNow time.Time) bool {
+	post.Status = config.POST_STATUS_PUBLISHED
+	post.PublishAt = nil
+	post.CreatedAt = timeNow
+	post.UpdatedAt = timeNow
+	post.CommentedAt = time.Unix(0, 0)
+
+	// Publish Post and update PostsCount and LastPostAt for User together.
+	// A Post that was published concurrently is only counted once.
+	var published bool
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(post).Where("status <> ?", config.POST_STATUS_PUBLISHED).UpdateColumns(map[string]interface{}{
+			"status":       post.Status,
+			"publish_at":   post.PublishAt,
+			"created_at":   post.CreatedAt,
+			"updated_at":   post.UpdatedAt,
+			"commented_at": post.CommentedAt,
+		})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		published = true
+		return incrementPostsCount(tx, user, timeNow)
+	})
+	if err != nil || !published {
+		return false
+	}
+	user.LastPostAt = timeNow
+
+	// Mentions in drafts are only notified now
+	notifications.NotifyMentions(user, notifications.MentionedUserIDs(post.ID, nil), post.ID, nil, post.Anonymous)
+	return true
+}
+
+// Publishes all scheduled Posts that are due.
+// Posts whose author is still on cooldown are left for the next run.
+func PublishScheduledPosts() {
+	var posts []models.Post
+	database.DB.Where("status = ? AND publish_at <= ?", config.POST_STATUS_SCHEDULED, time.Now()).Order("publish_at ASC, id ASC").Find(&posts)
+
+	for _, post := range posts {
+		var user models.User
+		database.DB.First(&user, post.UserID)
+		if user.ID == 0 {
+			continue
+		}
+
+		timeNow, canCreatePost := utils.CheckTimeIsAfter(user.LastPostAt, config.USER_POST_COOLDOWN)
+		if canCreatePost == false {
+			continue
+		}
+
+		if publishPost(&post, &user, timeNow) == false {
+			continue
+		}
+
+		fmt.Printf("Published scheduled post by %s.\n\tPost title: %s\n", user.Username, post.Title)
+	}
+}
+
+// Soft deletes a Post. Its Comments and Attachments are kept until the Post is purged.
+func RemovePost(post *models.Post, deletedBy *models.User) {
+	post.DeletedByID = deletedBy.ID
+
+	database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(post).UpdateColumn("deleted_by_id", post.DeletedByID).Error; err != nil {
+			return err
+		}
+
+		// A Post that was deleted concurrently is only uncounted once
+		result := tx.Delete(post)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		// Drafts and scheduled Posts are not counted towards PostsCount
+		if post.Status == config.POST_STATUS_PUBLISHED {
+			return tx.Model(&models.User{}).Where("id = ? AND posts_count > 0", post.UserID).UpdateColumn("posts_count", gorm.Expr("posts_count - 1")).Error
+		}
+		return nil
+	})
+}
+
+// Finds a soft deleted Post that user is allowed to restore.
+// Authors can only restore Posts they deleted themselves, admins can restore any Post.
+func findDeletedPost(c *gin.Context, user *models.User, postID uint) (models.Post, bool) {
+	var post models.Post
+	database.DB.Unscoped().Scopes(preloadPost).Where("deleted_at IS NOT NULL").First(&post, postID)
+	if post.ID == 0 || (user.Role != config.USER_ROLE_ADMIN && (post.UserID != user.ID || post.DeletedByID != user.ID)) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Deleted post not found."})
+		return post, false
+	}
+	return post, true
+}
+
+// Restores a soft deleted Post and counts it towards PostsCount of its author again
+func restorePost(post *models.Post) {
+	database.DB.Transaction(func(tx *gorm.DB) error {
+		// A Post that was restored concurrently is only counted once
+		result := tx.Unscoped().Model(post).Where("deleted_at IS NOT NULL").UpdateColumns(map[string]interface{}{
+			"deleted_at":    nil,
+			"deleted_by_id": 0,
+		})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		if post.Status == config.POST_STATUS_PUBLISHED {
+			return tx.Model(&models.User{}).Where("id = ?", post.UserID).UpdateColumn("posts_count", gorm.Expr("posts_count + 1")).Error
+		}
+		return nil
+	})
+	post.DeletedAt = gorm.DeletedAt{}
+	post.DeletedByID = 0
+}
+
+// Permanently deletes a Post along with its Comments and all of their Attachments
+func PurgePost(post *models.Post) {
+	commentIDs := database.DB.Unscoped().Model(&models.Comment{}).Select("id").Where("post_id = ?", post.ID)
+	attachments.DeleteAttachmentsFromContext(database.DB.Where("post_id = ? OR comment_id IN (?)", post.ID, commentIDs))
+
+	database.DB.Transaction(func(tx *gorm.DB) error {
+		// Comments that were not deleted on their own still count towards CommentsCount of their authors
+		type commenterCount struct {
+			UserID uint
+			Count  uint
+		}
+		var commenterCounts []commenterCount
+		tx.Model(&models.Comment{}).Select("user_id, COUNT(*) AS count").Where("post_id = ?", post.ID).Group("user_id").Scan(&commenterCounts)
+		for _, commenter := range commenterCounts {
+			if err := tx.Model(&models.User{}).Where("id = ?", commenter.UserID).UpdateColumn("comments_count", gorm.Expr("GREATEST(comments_count, ?) - ?", commenter.Count, commenter.Count)).Error; err != nil {
+				return err
+			}
+		}
+
+		// Comments, Poll, Stars and views are removed by CascadeDelete
+		return tx.Unscoped().Delete(post).Error
+	})
+}
+
+// Permanently deletes Posts (and everything attached to them) that were soft deleted
+// more than config.TRASH_RETENTION_PERIOD ago
+func PurgeDeletedPosts() {
+	var posts []models.Post
+	database.DB.Unscoped().Where("deleted_at < ?", time.Now().Add(-config.TRASH_RETENTION_PERIOD)).Find(&posts)
+
+	for _, post := range posts {
+		PurgePost(&post)
+	}
+
+	if len(posts) > 0 {
+		fmt.Printf("Purged %d deleted posts.\n", len(posts))
+	}
+}
+
+/* -------------------------------------------------------------------------- */
+/*                                 Post views                                 */
+/* -------------------------------------------------------------------------- */
+type postViewKey struct {
+	PostID uint
+	UserID uint
+}
+
+var postViewsMutex sync.Mutex
+
+// Last time each User was counted as viewing each Post (within config.POST_VIEW_DEDUP_WINDOW)
+var lastPostViews = map[postViewKey]time.Time{}
+
+// Views waiting to be saved by FlushPostViews
+var pendingPostViews []models.PostView
+
+// Buffers a view of post by user. Views by the author are not counted.
+func RecordPostView(post *models.Post, user *models.User) {
+	if post.UserID == user.ID || post.Status != config.POST_STATUS_PUBLISHED {
+		return
+	}
+
+	postViewsMutex.Lock()
+	defer postViewsMutex.Unlock()
+
+	timeNow := time.Now()
+	key := postViewKey{PostID: post.ID, UserID: user.ID}
+	if lastViewAt, found := lastPostViews[key]; found && timeNow.Sub(lastViewAt) < config.POST_VIEW_DEDUP_WINDOW {
+		return
+	}
+
+	lastPostViews[key] = timeNow
+	pendingPostViews = append(pendingPostViews, models.PostView{
+		BaseModel: models.BaseModel{CreatedAt: timeNow},
+		PostID:    post.ID,
+		UserID:    user.ID,
+	})
+}
+
+// Saves buffered views in a single batch and increments ViewsCount of the viewed Posts.
+// Views already saved within config.POST_VIEW_DEDUP_WINDOW (by any instance) are dropped.
+func FlushPostViews() {
+	postViewsMutex.Lock()
+	views := pendingPostViews
+	pendingPostViews = nil
+
+	// Forget views that are outside of the dedup window
+	for key, lastViewAt := range lastPostViews {
+		if time.Since(lastViewAt) >= config.POST_VIEW_DEDUP_WINDOW {
+			delete(lastPostViews, key)
+		}
+	}
+	postViewsMutex.Unlock()
+
+	if len(views) == 0 {
+		return
+	}
+
+	var postIDs []uint
+	since := views[0].CreatedAt
+	for _, view := range views {
+		postIDs = append(postIDs, view.PostID)
+		if view.CreatedAt.Before(since) {
+			since = view.CreatedAt
+		}
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Posts may have been deleted since they were viewed.
+		// Locking them also serializes flushes from other instances that saw the same Posts.
+		var existingPostIDs []uint
+		if err := tx.Model(&models.Post{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", postIDs).Pluck("id", &existingPostIDs).Error; err != nil {
+			return err
+		}
+
+		existingPosts := map[uint]bool{}
+		for _, postID := range existingPostIDs {
+			existingPosts[postID] = true
+		}
+
+		// lastPostViews is per instance and empty after a restart,
+		// so dedup against views that were already saved as well
+		var savedViews []struct {
+			PostID     uint
+			UserID     uint
+			LastViewAt time.Time
+		}
+		if err := tx.Model(&models.PostView{}).
+			Select("post_id, user_id, MAX(created_at) AS last_view_at").
+			Where("post_id IN ? AND created_at > ?", existingPostIDs, since.Add(-config.POST_VIEW_DEDUP_WINDOW)).
+			Group("post_id, user_id").
+			Scan(&savedViews).Error; err != nil {
+			return err
+		}
+
+		lastSavedViews := map[postViewKey]time.Time{}
+		for _, savedView := range savedViews {
+			lastSavedViews[postViewKey{PostID: savedView.PostID, UserID: savedView.UserID}] = savedView.LastViewAt
+		}
+
+		viewsCount := map[uint]uint{}
+		var validViews []models.PostView
+		for _, view := range views {
+			if !existingPosts[view.PostID] {
+				continue
+			}
+
+			key := postViewKey{PostID: view.PostID, UserID: view.UserID}
+			if lastViewAt, found := lastSavedViews[key]; found && view.CreatedAt.Sub(lastViewAt) < config.POST_VIEW_DEDUP_WINDOW {
+				continue
+			}
+
+			lastSavedViews[key] = view.CreatedAt
+			validViews = append(validViews, view)
+			viewsCount[view.PostID] += 1
+		}
+
+		if len(validViews) == 0 {
+			return nil
+		}
+
+		if err := tx.CreateInBatches(&validViews, 500).Error; err != nil {
+			return err
+		}
+
+		for postID, count := range viewsCount {
+			if err := tx.Model(&models.Post{}).Where("id = ?", postID).UpdateColumn("views_count", gorm.Expr("views_count + ?", count)).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		fmt.Printf("Failed to flush %d post views: %s\n", len(views), err)
+	}
+}
+
+/* -------------------------------------------------------------------------- */
+/*                               Post analytics                               */
+/* -------------------------------------------------------------------------- */
+type dailyCount struct {
+	Day   string
+	Count uint
+}
+
+// Counts rows of model for postID grouped by the day (in database.Location) they were created, starting from since.
+// Soft deleted rows are not counted.
+func countPerDay(model interface{}, postID uint, since time.Time) map[string]uint {
+	var counts []dailyCount
+	database.DB.Model(model).
+		Select("DATE_FORMAT(created_at, '%Y-%m-%d') AS day, COUNT(*) AS count").
+		Where("post_id = ? AND created_at >= ?", postID, since).
+		Group("day").
+		Scan(&counts)
+
+	countsByDay := map[string]uint{}
+	for _, count := range counts {
+		countsByDay[count.Day] = count.Count
+	}
+	return countsByDay
+}
+
+type PostAnalyticsDayResponse struct {
+	Date     string `json:"date" binding:"required"`
+	Views    uint   `json:"views" binding:"required"`
+	Comments uint   `json:"comments" binding:"required"`
+	Stars    uint   `json:"stars" binding:"required"`
+}
+
+type PostAnalyticsResponse struct {
+	PostID        uint                       `json:"postId" binding:"required"`
+	ViewsCount    uint                       `json:"viewsCount" binding:"required"`
+	CommentsCount uint                       `json:"commentsCount" binding:"required"`
+	StarsCount    uint                       `json:"starsCount" binding:"required"`
+	Days          []PostAnalyticsDayResponse `json:"days" binding:"required"`
+}
+
+// Builds daily views, comments and stars of post for the last numberOfDays days (including today)
+func CreatePostAnalyticsResponse(post *models.Post, numberOfDays int) PostAnalyticsResponse {
+	// Days are in the time zone created_at is stored in, so that they match the days grouped by the database
+	timeNow := time.Now().In(database.Location)
+	today := time.Date(timeNow.Year(), timeNow.Month(), timeNow.Day(), 0, 0, 0, 0, timeNow.Location())
+	since := today.AddDate(0, 0, -(numberOfDays - 1))
+
+	views := countPerDay(&models.PostView{}, post.ID, since)
+	comments := countPerDay(&models.Comment{}, post.ID, since)
+	stars := countPerDay(&models.Star{}, post.ID, since)
+
+	daysResponse := []PostAnalyticsDayResponse{}
+	for day := since; !day.After(today); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		daysResponse = append(daysResponse, PostAnalyticsDayResponse{
+			Date:     date,
+			Views:    views[date],
+			Comments: comments[date],
+			Stars:    stars[date],
+		})
+	}
+
+	return PostAnalyticsResponse{
+		PostID:        post.ID,
+		ViewsCount:    post.ViewsCount,
+		CommentsCount: post.CommentsCount,
+		StarsCount:    post.StarsCount,
+		Days:          daysResponse,
+	}
+}
+
 type PostResponse struct {
 	ID            uint   `json:"id" binding:"required"`
 	Title         string `json:"title" binding:"required"`
 	Tag           string `json:"tag" binding:"required"`
 	Text          string `json:"text" binding:"required"`
+	TextHTML      string `json:"textHtml" binding:"required"`
+	Status        string `json:"status" binding:"required"`
+	PublishAt     int64  `json:"publishAt"`
 	Author        string `json:"author" binding:"required"`
 	UserID        uint   `json:"userId" binding:"required"`
+	Anonymous     bool   `json:"anonymous" binding:"required"`
 	CommentsCount uint   `json:"commentsCount" binding:"required"`
 	CommentedAt   int64  `json:"commentedAt" binding:"required"`
 	StarsCount    uint   `json:"starsCount" binding:"required"`
-	CreatedAt     int64  `json:"createdAt" binding:"required"`
-	UpdatedAt     int64  `json:"updatedAt" binding:"required"`
+	ViewsCount    uint   `json:"viewsCount" binding:"required"`
+	Locked        bool   `json:"locked" binding:"required"`
+	LockedReason  string `json:"lockedReason" binding:"required"`
+	Hidden        bool   `json:"hidden" binding:"required"`
+	DeletedAt     int64  `json:"deletedAt"`
+
+	// Only Q&A Posts can be answered, AcceptedCommentID is 0 if there is no accepted answer
+	Question          bool `json:"question" binding:"required"`
+	Answered          bool `json:"answered" binding:"required"`
+	AcceptedCommentID uint `json:"acceptedCommentId"`
+
+	Attachments []attachments.AttachmentResponse `json:"attachments" binding:"required"`
+	Poll        *polls.PollResponse              `json:"poll"`
+
+	CreatedAt int64 `json:"createdAt" binding:"required"`
+	UpdatedAt int64 `json:"updatedAt" binding:"required"`
 }
 
-// Convert a Post Model into a JSON format
-func CreatePostResponse(post *models.Post) PostResponse {
+// Convert a Post Model into a JSON format as seen by user
+func CreatePostResponse(post *models.Post, user *models.User) PostResponse {
+	var myPollVotes map[uint][]uint
+	if post.Poll != nil {
+		myPollVotes = polls.FindMyVotes([]uint{post.Poll.ID}, user)
+	}
+	return createPostResponse(post, user, myPollVotes)
+}
+
+// Same as CreatePostResponse but uses poll votes prefetched with polls.FindMyVotes
+func createPostResponse(post *models.Post, user *models.User, myPollVotes map[uint][]uint) PostResponse {
+	var publishAt int64
+	if post.PublishAt != nil {
+		publishAt = post.PublishAt.Unix()
+	}
+
+	var deletedAt int64
+	if post.DeletedAt.Valid {
+		deletedAt = post.DeletedAt.Time.Unix()
+	}
+
+	// Anonymous Posts show a pseudonym and only reveal UserID to the author and moderators
+	author, userID := post.Author, post.UserID
+	attachmentsResponse := attachments.CreateAttachmentsResponse(post.Attachments)
+	if post.Anonymous {
+		author = utils.Pseudonym(post.ID, post.UserID)
+		if !auth.CanSeeAnonymousAuthor(user, post.UserID) {
+			userID = 0
+			for i := range attachmentsResponse {
+				attachmentsResponse[i].UserID = 0
+			}
+		}
+	}
+
+	var acceptedCommentID uint
+	if post.AcceptedCommentID != nil {
+		acceptedCommentID = *post.AcceptedCommentID
+	}
+
 	return PostResponse{
-		ID:            post.ID,
-		Title:         post.Title,
-		Tag:           post.Tag,
-		Text:          post.Text,
-		Author:        post.Author,
-		UserID:        post.UserID,
-		CommentsCount: post.CommentsCount,
-		CommentedAt:   post.CommentedAt.Unix(),
-		StarsCount:    post.StarsCount,
-		CreatedAt:     post.CreatedAt.Unix(),
-		UpdatedAt:     post.UpdatedAt.Unix(),
+		ID:                post.ID,
+		Title:             post.Title,
+		Tag:               post.Tag,
+		Text:              post.Text,
+		TextHTML:          post.TextHTML,
+		Status:            post.Status,
+		PublishAt:         publishAt,
+		Author:            author,
+		UserID:            userID,
+		Anonymous:         post.Anonymous,
+		CommentsCount:     post.CommentsCount,
+		CommentedAt:       post.CommentedAt.Unix(),
+		StarsCount:        post.StarsCount,
+		ViewsCount:        post.ViewsCount,
+		Locked:            post.Locked,
+		LockedReason:      post.LockedReason,
+		Hidden:            post.Hidden,
+		DeletedAt:         deletedAt,
+		Question:          config.QA_TAGS[post.Tag],
+		Answered:          post.AcceptedCommentID != nil,
+		AcceptedCommentID: acceptedCommentID,
+		Attachments:       attachmentsResponse,
+		Poll:              polls.CreatePollResponseFromVotes(post.Poll, myPollVotes),
+		CreatedAt:         post.CreatedAt.Unix(),
+		UpdatedAt:         post.UpdatedAt.Unix(),
 	}
 }
 
@@ -55,10 +731,19 @@ type GetPostsResponse struct {
 }
 
 // Bundles and convert multiple Post models into a JSON format
-func CreatePostsResponse(posts *[]models.Post, totalPostsCount int64) GetPostsResponse {
+func CreatePostsResponse(posts *[]models.Post, totalPostsCount int64, user *models.User) GetPostsResponse {
+	// Fetch user's votes for every Poll on the page at once
+	var pollIDs []uint
+	for _, post := range *posts {
+		if post.Poll != nil {
+			pollIDs = append(pollIDs, post.Poll.ID)
+		}
+	}
+	myPollVotes := polls.FindMyVotes(pollIDs, user)
+
 	var postsResponse []PostResponse
 	for _, post := range *posts {
-		postReponse := CreatePostResponse(&post)
+		postReponse := createPostResponse(&post, user, myPollVotes)
 		postsResponse = append(postsResponse, postReponse)
 	}
 
@@ -99,14 +784,14 @@ func GetPostsFromContext(dbContext *gorm.DB, perPage uint, pageNumber uint, sort
 		// Reverse page number based on totalPostsCount
 		leftOverRecords := math.Min(float64(clampedPerPage), float64(totalPostsCount-offsetPostsCount))
 		offsetPostsCount = totalPostsCount - offsetPostsCount - clampedPerPage
-		dbContext.Limit(int(leftOverRecords)).Order(defaultSortOption).Offset(int(offsetPostsCount)).Find(&posts)
+		dbContext.Scopes(preloadPost).Limit(int(leftOverRecords)).Order(defaultSortOption).Offset(int(offsetPostsCount)).Find(&posts)
 
 		// Reverse the page results for descending order
 		for i, j := 0, len(posts)-1; i < j; i, j = i+1, j-1 {
 			posts[i], posts[j] = posts[j], posts[i]
 		}
 	} else {
-		dbContext.Limit(int(clampedPerPage)).Order(defaultSortOption).Offset(int(offsetPostsCount)).Find(&posts)
+		dbContext.Scopes(preloadPost).Limit(int(clampedPerPage)).Order(defaultSortOption).Offset(int(offsetPostsCount)).Find(&posts)
 	}
 
 	return posts, totalPostsCount
@@ -4,8 +4,26 @@ import "github.com/gin-gonic/gin"
 
 func RegisterRoutes(r *gin.Engine) {
 	r.GET("posts/get/:perPage/:pageNumber/:sortOption/:sortOrder/:filterUserId/:filterTag", GetPosts)
+	r.GET("posts/list", ListPosts)
 	r.GET("posts/getbyid/:postId", GetPostByID)
 	r.POST("posts/create", CreatePost)
 	r.POST("posts/updatetext", UpdatePostText)
 	r.DELETE("posts/delete/:postId", DeletePost)
+	r.GET("posts/trash/:perPage/:pageNumber", GetTrash)
+	r.POST("posts/restore", RestorePost)
+	r.POST("posts/lock", LockPost)
+	r.POST("posts/unlock", UnlockPost)
+	r.GET("posts/drafts/:perPage/:pageNumber", GetDrafts)
+	r.POST("posts/updatedraft", UpdateDraft)
+	r.POST("posts/publish", PublishPost)
+	r.POST("posts/star", StarPost)
+	r.DELETE("posts/unstar/:postId", UnstarPost)
+	r.GET("posts/analytics/:postId/:days", GetPostAnalytics)
+	r.GET("posts/following/:perPage/:pageNumber/:sortOption/:sortOrder", GetFollowingFeed)
+	r.POST("posts/followtag", FollowTag)
+	r.DELETE("posts/unfollowtag/:tag", UnfollowTag)
+	r.GET("posts/followedtags", GetFollowedTags)
+	r.POST("posts/subscribe", SubscribeToPost)
+	r.DELETE("posts/unsubscribe/:postId", UnsubscribeFromPost)
+	r.GET("posts/subscription/:postId", GetSubscription)
 }
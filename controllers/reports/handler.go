@@ -0,0 +1,374 @@
+package reports
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mfjkri/OneNUS-Backend/config"
+	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/controllers/comments"
+	"github.com/mfjkri/OneNUS-Backend/controllers/posts"
+	"github.com/mfjkri/OneNUS-Backend/database"
+	"github.com/mfjkri/OneNUS-Backend/models"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                      ReportPost | route: /reports/post                     */
+/* -------------------------------------------------------------------------- */
+type ReportPostRequest struct {
+	PostID  uint   `json:"postId" binding:"required"`
+	Reason  string `json:"reason" binding:"required"`
+	Details string `json:"details"`
+}
+
+func ReportPost(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json ReportPostRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Check that Reason and Details are valid
+	details, valid := validateReason(c, json.Reason, json.Details)
+	if valid == false {
+		return
+	}
+
+	// Find Post from PostID
+	var post models.Post
+	database.DB.First(&post, json.PostID)
+	if post.ID == 0 || post.Status != config.POST_STATUS_PUBLISHED || (!post.Anonymous && auth.IsBlocked(user.ID, post.UserID)) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Post not found."})
+		return
+	}
+
+	// Users cannot report their own Posts
+	if post.UserID == user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You cannot report your own post."})
+		return
+	}
+
+	// Create new Report
+	report := models.Report{
+		TargetType:   config.REPORT_TARGET_POST,
+		TargetID:     post.ID,
+		TargetUserID: post.UserID,
+		Reason:       json.Reason,
+		Details:      details,
+	}
+	if createReport(c, &user, &report) == false {
+		return
+	}
+
+	fmt.Printf("%s has reported a post.\n\tPost title: %s\n\tReason: %s\n", user.Username, post.Title, report.Reason)
+
+	// Return new Report data
+	c.JSON(http.StatusAccepted, CreateReportResponse(&report, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                   ReportComment | route: /reports/comment                  */
+/* -------------------------------------------------------------------------- */
+type ReportCommentRequest struct {
+	CommentID uint   `json:"commentId" binding:"required"`
+	Reason    string `json:"reason" binding:"required"`
+	Details   string `json:"details"`
+}
+
+func ReportComment(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json ReportCommentRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Check that Reason and Details are valid
+	details, valid := validateReason(c, json.Reason, json.Details)
+	if valid == false {
+		return
+	}
+
+	// Find Comment from CommentID
+	var comment models.Comment
+	database.DB.First(&comment, json.CommentID)
+	if comment.ID == 0 || !auth.CanSeeComment(&user, &comment) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Comment not found."})
+		return
+	}
+
+	// Comments can only be reported on Posts that user can see
+	var post models.Post
+	database.DB.First(&post, comment.PostID)
+	if post.ID == 0 || post.Status != config.POST_STATUS_PUBLISHED || !auth.CanSeePost(&user, &post) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Comment not found."})
+		return
+	}
+
+	// Users cannot report their own Comments
+	if comment.UserID == user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You cannot report your own comment."})
+		return
+	}
+
+	// Create new Report
+	report := models.Report{
+		TargetType:   config.REPORT_TARGET_COMMENT,
+		TargetID:     comment.ID,
+		TargetUserID: comment.UserID,
+		Reason:       json.Reason,
+		Details:      details,
+	}
+	if createReport(c, &user, &report) == false {
+		return
+	}
+
+	fmt.Printf("%s has reported a comment.\n\tComment text: %s\n\tReason: %s\n", user.Username, comment.Text, report.Reason)
+
+	// Return new Report data
+	c.JSON(http.StatusAccepted, CreateReportResponse(&report, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                      ReportUser | route: /reports/user                     */
+/* -------------------------------------------------------------------------- */
+type ReportUserRequest struct {
+	UserID  uint   `json:"userId" binding:"required"`
+	Reason  string `json:"reason" binding:"required"`
+	Details string `json:"details"`
+}
+
+func ReportUser(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json ReportUserRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Check that Reason and Details are valid
+	details, valid := validateReason(c, json.Reason, json.Details)
+	if valid == false {
+		return
+	}
+
+	// Find User from UserID
+	targetUser, found := auth.FindUserFromID(c, json.UserID)
+	if found == false {
+		return
+	}
+
+	// Users cannot report themselves
+	if targetUser.ID == user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You cannot report yourself."})
+		return
+	}
+
+	// Create new Report
+	report := models.Report{
+		TargetType:   config.REPORT_TARGET_USER,
+		TargetID:     targetUser.ID,
+		TargetUserID: targetUser.ID,
+		Reason:       json.Reason,
+		Details:      details,
+	}
+	if createReport(c, &user, &report) == false {
+		return
+	}
+
+	fmt.Printf("%s has reported %s.\n\tReason: %s\n", user.Username, targetUser.Username, report.Reason)
+
+	// Return new Report data
+	c.JSON(http.StatusAccepted, CreateReportResponse(&report, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                         GetReportQueue | route: ...                        */
+/* -------------------------------------------------------------------------- */
+// route: /reports/queue/:perPage/:pageNumber
+type GetReportQueueRequest struct {
+	PerPage    uint `uri:"perPage" binding:"required"`
+	PageNumber uint `uri:"pageNumber" binding:"required"`
+}
+
+func GetReportQueue(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetReportQueueRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Check User is a moderator
+	if !auth.IsModerator(&user) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You do not have valid permissions."})
+		return
+	}
+
+	// Return pending Reports
+	c.JSON(http.StatusAccepted, GetReportQueueFromDB(json.PerPage, json.PageNumber))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                   ResolveReport | route: /reports/resolve                  */
+/* -------------------------------------------------------------------------- */
+type ResolveReportRequest struct {
+	ReportID uint   `json:"reportId" binding:"required"`
+	Outcome  string `json:"outcome" binding:"required"`
+	Note     string `json:"note"`
+}
+
+func ResolveReport(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json ResolveReportRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Check User is a moderator
+	if !auth.IsModerator(&user) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You do not have valid permissions."})
+		return
+	}
+
+	// Check that Note is valid
+	note, valid := validateNote(c, json.Note)
+	if valid == false {
+		return
+	}
+
+	// Find Report from ReportID
+	report, found := findPendingReport(c, json.ReportID)
+	if found == false {
+		return
+	}
+
+	// Apply Outcome to the reported target
+	switch {
+	case json.Outcome == config.REPORT_OUTCOME_WARNED:
+		// Nothing to apply, the outcome is only recorded
+
+	case json.Outcome == config.REPORT_OUTCOME_REMOVED && report.TargetType == config.REPORT_TARGET_POST:
+		var post models.Post
+		database.DB.First(&post, report.TargetID)
+		if post.ID != 0 {
+			posts.RemovePost(&post, &user)
+		}
+
+	case json.Outcome == config.REPORT_OUTCOME_REMOVED && report.TargetType == config.REPORT_TARGET_COMMENT:
+		var comment models.Comment
+		database.DB.First(&comment, report.TargetID)
+		if comment.ID != 0 {
+			comments.RemoveComment(&comment, &user)
+		}
+
+	case json.Outcome == config.REPORT_OUTCOME_HIDDEN && report.TargetType != config.REPORT_TARGET_USER:
+		setTargetHidden(report.TargetType, report.TargetID, true)
+
+	case json.Outcome == config.REPORT_OUTCOME_LOCKED && report.TargetType == config.REPORT_TARGET_POST:
+		lockedReason := note
+		if lockedReason == "" {
+			lockedReason = "Locked by moderators after being reported."
+		}
+		database.DB.Model(&models.Post{}).Where("id = ?", report.TargetID).UpdateColumns(map[string]interface{}{
+			"locked":        true,
+			"locked_reason": lockedReason,
+			"locked_by_id":  user.ID,
+		})
+
+	default:
+		c.JSON(http.StatusForbidden, gin.H{"message": "Invalid outcome for this report."})
+		return
+	}
+
+	// Resolve all pending Reports against the same target
+	closeReports(&report, &user, config.REPORT_STATUS_RESOLVED, json.Outcome, note)
+
+	fmt.Printf("%s has resolved reports against a %s.\n\tOutcome: %s\n", user.Username, report.TargetType, report.Outcome)
+
+	// Return resolved Report data
+	c.JSON(http.StatusAccepted, CreateReportResponse(&report, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                   DismissReport | route: /reports/dismiss                  */
+/* -------------------------------------------------------------------------- */
+type DismissReportRequest struct {
+	ReportID uint   `json:"reportId" binding:"required"`
+	Note     string `json:"note"`
+}
+
+func DismissReport(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json DismissReportRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Check User is a moderator
+	if !auth.IsModerator(&user) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You do not have valid permissions."})
+		return
+	}
+
+	// Check that Note is valid
+	note, valid := validateNote(c, json.Note)
+	if valid == false {
+		return
+	}
+
+	// Find Report from ReportID
+	report, found := findPendingReport(c, json.ReportID)
+	if found == false {
+		return
+	}
+
+	// Dismiss all pending Reports against the same target and unhide it
+	closeReports(&report, &user, config.REPORT_STATUS_DISMISSED, config.REPORT_OUTCOME_NONE, note)
+	setTargetHidden(report.TargetType, report.TargetID, false)
+
+	fmt.Printf("%s has dismissed reports against a %s.\n", user.Username, report.TargetType)
+
+	// Return dismissed Report data
+	c.JSON(http.StatusAccepted, CreateReportResponse(&report, &user))
+}
@@ -0,0 +1,268 @@
+package reports
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mfjkri/OneNUS-Backend/config"
+	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/database"
+	"github.com/mfjkri/OneNUS-Backend/models"
+	"github.com/mfjkri/OneNUS-Backend/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var validReasons = map[string]bool{
+	config.REPORT_REASON_SPAM:           true,
+	config.REPORT_REASON_HARASSMENT:     true,
+	config.REPORT_REASON_HATE:           true,
+	config.REPORT_REASON_NSFW:           true,
+	config.REPORT_REASON_MISINFORMATION: true,
+	config.REPORT_REASON_OTHER:          true,
+}
+
+// Validates the Reason and (optional) Details of a report.
+// Details are required when the Reason is "other".
+func validateReason(c *gin.Context, reason string, details string) (string, bool) {
+	if !validReasons[reason] {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Invalid report reason."})
+		return "", false
+	}
+
+	if details == "" && reason != config.REPORT_REASON_OTHER {
+		return "", true
+	}
+
+	details, err := utils.ValidateText("Details", details, config.MAX_REPORT_DETAILS_CHAR)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		return "", false
+	}
+	return details, true
+}
+
+// Validates the (optional) moderator Note of a resolved or dismissed report
+func validateNote(c *gin.Context, note string) (string, bool) {
+	if note == "" {
+		return "", true
+	}
+
+	note, err := utils.ValidateText("Note", note, config.MAX_REPORT_NOTE_CHAR)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		return "", false
+	}
+	return note, true
+}
+
+// Number of pending reports against a target
+func countPendingReports(targetType string, targetID uint) int64 {
+	var count int64
+	database.DB.Model(&models.Report{}).Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, config.REPORT_STATUS_PENDING).Count(&count)
+	return count
+}
+
+// Hides or unhides a reported Post or Comment without touching UpdatedAt
+func setTargetHidden(targetType string, targetID uint, hidden bool) {
+	switch targetType {
+	case config.REPORT_TARGET_POST:
+		database.DB.Model(&models.Post{}).Where("id = ?", targetID).UpdateColumn("hidden", hidden)
+	case config.REPORT_TARGET_COMMENT:
+		database.DB.Model(&models.Comment{}).Where("id = ?", targetID).UpdateColumn("hidden", hidden)
+	}
+}
+
+// Saves a new report by user, unless user already has a pending report against the target.
+// Posts and Comments are hidden once they reach config.REPORTS_TO_HIDE pending reports.
+func createReport(c *gin.Context, user *models.User, report *models.Report) bool {
+	alreadyReported := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the reporter so that concurrent reports of the same target are checked one at a time
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", user.ID).Find(&models.User{}).Error; err != nil {
+			return err
+		}
+
+		var pendingReportsCount int64
+		tx.Model(&models.Report{}).Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status = ?", user.ID, report.TargetType, report.TargetID, config.REPORT_STATUS_PENDING).Count(&pendingReportsCount)
+		if pendingReportsCount > 0 {
+			alreadyReported = true
+			return nil
+		}
+
+		report.ReporterID = user.ID
+		report.Status = config.REPORT_STATUS_PENDING
+		return tx.Create(report).Error
+	})
+	if alreadyReported {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You have already reported this " + report.TargetType + "."})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to create report. Try again later."})
+		return false
+	}
+
+	if countPendingReports(report.TargetType, report.TargetID) >= int64(config.REPORTS_TO_HIDE) {
+		setTargetHidden(report.TargetType, report.TargetID, true)
+	}
+
+	return true
+}
+
+// Finds a pending report
+func findPendingReport(c *gin.Context, reportID uint) (models.Report, bool) {
+	var report models.Report
+	database.DB.Where("status = ?", config.REPORT_STATUS_PENDING).First(&report, reportID)
+	if report.ID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Report not found."})
+		return report, false
+	}
+	return report, true
+}
+
+// Closes all pending reports against the target of report with the same status and outcome
+func closeReports(report *models.Report, moderator *models.User, status string, outcome string, note string) {
+	timeNow := time.Now()
+	database.DB.Model(&models.Report{}).
+		Where("target_type = ? AND target_id = ? AND status = ?", report.TargetType, report.TargetID, config.REPORT_STATUS_PENDING).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"outcome":        outcome,
+			"note":           note,
+			"resolved_by_id": moderator.ID,
+			"resolved_at":    timeNow,
+		})
+
+	report.Status = status
+	report.Outcome = outcome
+	report.Note = note
+	report.ResolvedByID = moderator.ID
+	report.ResolvedAt = &timeNow
+}
+
+// Short description of the reported target shown in the moderation queue,
+// and whether the target was posted anonymously
+func describeTarget(report *models.Report) (string, bool) {
+	switch report.TargetType {
+	case config.REPORT_TARGET_POST:
+		var post models.Post
+		database.DB.Unscoped().First(&post, report.TargetID)
+		return post.Title, post.Anonymous
+	case config.REPORT_TARGET_COMMENT:
+		var comment models.Comment
+		database.DB.Unscoped().First(&comment, report.TargetID)
+		return comment.Text, comment.Anonymous
+	case config.REPORT_TARGET_USER:
+		var user models.User
+		database.DB.First(&user, report.TargetID)
+		return user.Username, false
+	}
+	return "", false
+}
+
+type ReportResponse struct {
+	ID           uint   `json:"id" binding:"required"`
+	ReporterID   uint   `json:"reporterId" binding:"required"`
+	TargetType   string `json:"targetType" binding:"required"`
+	TargetID     uint   `json:"targetId" binding:"required"`
+	TargetUserID uint   `json:"targetUserId"`
+	Reason       string `json:"reason" binding:"required"`
+	Details      string `json:"details" binding:"required"`
+	Status       string `json:"status" binding:"required"`
+	Outcome      string `json:"outcome" binding:"required"`
+	Note         string `json:"note" binding:"required"`
+	ResolvedByID uint   `json:"resolvedById" binding:"required"`
+	ResolvedAt   int64  `json:"resolvedAt"`
+	CreatedAt    int64  `json:"createdAt" binding:"required"`
+}
+
+// Convert a Report Model into a JSON format as seen by viewer.
+// TargetUserID is only shown to moderators and never for anonymous targets.
+func CreateReportResponse(report *models.Report, viewer *models.User) ReportResponse {
+	showTargetUser := false
+	if auth.IsModerator(viewer) {
+		_, anonymous := describeTarget(report)
+		showTargetUser = !anonymous
+	}
+	return createReportResponse(report, showTargetUser)
+}
+
+func createReportResponse(report *models.Report, showTargetUser bool) ReportResponse {
+	var resolvedAt int64
+	if report.ResolvedAt != nil {
+		resolvedAt = report.ResolvedAt.Unix()
+	}
+
+	var targetUserID uint
+	if showTargetUser {
+		targetUserID = report.TargetUserID
+	}
+
+	return ReportResponse{
+		ID:           report.ID,
+		ReporterID:   report.ReporterID,
+		TargetType:   report.TargetType,
+		TargetID:     report.TargetID,
+		TargetUserID: targetUserID,
+		Reason:       report.Reason,
+		Details:      report.Details,
+		Status:       report.Status,
+		Outcome:      report.Outcome,
+		Note:         report.Note,
+		ResolvedByID: report.ResolvedByID,
+		ResolvedAt:   resolvedAt,
+		CreatedAt:    report.CreatedAt.Unix(),
+	}
+}
+
+type QueuedReportResponse struct {
+	ReportResponse
+	TargetPreview      string `json:"targetPreview" binding:"required"`
+	TargetReportsCount int64  `json:"targetReportsCount" binding:"required"`
+}
+
+type GetReportQueueResponse struct {
+	Reports      []QueuedReportResponse `json:"reports" binding:"required"`
+	ReportsCount int64                  `json:"reportsCount" binding:"required"`
+}
+
+// Fetches a page of pending reports (oldest first) for the moderation queue
+func GetReportQueueFromDB(perPage uint, pageNumber uint) GetReportQueueResponse {
+	var reports []models.Report
+
+	// Limit PerPage to config.MAX_PER_PAGE
+	clampedPerPage := int64(math.Min(config.MAX_PER_PAGE, float64(perPage)))
+	offsetReportsCount := int64(pageNumber-1) * clampedPerPage
+
+	// Get total count for pending Reports
+	dbContext := database.DB.Model(&models.Report{}).Where("status = ?", config.REPORT_STATUS_PENDING)
+	var totalReportsCount int64
+	dbContext.Count(&totalReportsCount)
+
+	reportsResponse := []QueuedReportResponse{}
+
+	// If we are request beyond the bounds of total count, return nothing
+	if (offsetReportsCount < 0) || (offsetReportsCount > totalReportsCount) {
+		return GetReportQueueResponse{Reports: reportsResponse, ReportsCount: 0}
+	}
+
+	dbContext.Order("created_at ASC, id ASC").Limit(int(clampedPerPage)).Offset(int(offsetReportsCount)).Find(&reports)
+
+	// Only moderators can fetch the queue
+	for _, report := range reports {
+		preview, anonymous := describeTarget(&report)
+		reportsResponse = append(reportsResponse, QueuedReportResponse{
+			ReportResponse:     createReportResponse(&report, !anonymous),
+			TargetPreview:      preview,
+			TargetReportsCount: countPendingReports(report.TargetType, report.TargetID),
+		})
+	}
+
+	return GetReportQueueResponse{
+		Reports:      reportsResponse,
+		ReportsCount: totalReportsCount,
+	}
+}
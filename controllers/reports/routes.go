@@ -0,0 +1,12 @@
+package reports
+
+import "github.com/gin-gonic/gin"
+
+func RegisterRoutes(r *gin.Engine) {
+	r.POST("reports/post", ReportPost)
+	r.POST("reports/comment", ReportComment)
+	r.POST("reports/user", ReportUser)
+	r.GET("reports/queue/:perPage/:pageNumber", GetReportQueue)
+	r.POST("reports/resolve", ResolveReport)
+	r.POST("reports/dismiss", DismissReport)
+}
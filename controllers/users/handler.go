@@ -4,13 +4,20 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mfjkri/OneNUS-Backend/config"
 	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/controllers/comments"
+	"github.com/mfjkri/OneNUS-Backend/controllers/notifications"
+	"github.com/mfjkri/OneNUS-Backend/controllers/posts"
 	"github.com/mfjkri/OneNUS-Backend/database"
 	"github.com/mfjkri/OneNUS-Backend/models"
+	"github.com/mfjkri/OneNUS-Backend/storage"
 	"github.com/mfjkri/OneNUS-Backend/utils"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 /* -------------------------------------------------------------------------- */
@@ -34,12 +41,51 @@ func GetUserFromID(c *gin.Context) {
 		return
 	}
 
-	targetUser, found := auth.FindUserFromID(c, json.UserID)
+	// Users blocked either way cannot see each other's profile
+	targetUser, found := findVisibleUser(c, &user, json.UserID)
+	if found == false {
+		return
+	}
+
+	fmt.Printf("%s has requested for: %s\n", user.Username, targetUser.Username)
+
+	// Return fetch User
+	c.JSON(http.StatusAccepted, CreateUserResponse(&targetUser))
+}
+
+/* -------------------------------------------------------------------------- */
+/*         GetUserFromUsername | route: /users/getbyusername/:username        */
+/* -------------------------------------------------------------------------- */
+type GetUserFromUsernameRequest struct {
+	Username string `uri:"username" binding:"required"`
+}
 
+func GetUserFromUsername(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
 	if found == false {
 		return
 	}
 
+	// Parse RequestBody
+	var json GetUserFromUsernameRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Old usernames resolve to the User's current profile
+	targetUser, found := findUserFromUsername(c, json.Username)
+	if found == false {
+		return
+	}
+
+	// Users blocked either way cannot see each other's profile
+	if auth.IsBlocked(user.ID, targetUser.ID) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "User not found."})
+		return
+	}
+
 	fmt.Printf("%s has requested for: %s\n", user.Username, targetUser.Username)
 
 	// Return fetch User
@@ -47,13 +93,14 @@ func GetUserFromID(c *gin.Context) {
 }
 
 /* -------------------------------------------------------------------------- */
-/*                     UpdateBio | route: /users/updatebio                    */
+/*                ChangeUsername | route: /users/changeusername               */
 /* -------------------------------------------------------------------------- */
-type UpdateBioRequest struct {
-	Bio string `json:"bio" binding:"required"`
+type ChangeUsernameRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
 }
 
-func UpdateBio(c *gin.Context) {
+func ChangeUsername(c *gin.Context) {
 	// Check that RequestUser is authenticated
 	user, found := auth.VerifyAuth(c)
 	if found == false {
@@ -61,23 +108,235 @@ func UpdateBio(c *gin.Context) {
 	}
 
 	// Parse RequestBody
-	var json UpdateBioRequest
+	var json ChangeUsernameRequest
 	if err := c.ShouldBindJSON(&json); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
 		return
 	}
 
-	// Check that new bio does not contain illegal characters
-	if !utils.ContainsValidCharactersOnly(json.Bio) {
-		c.JSON(http.StatusForbidden, gin.H{"message": "Bio contains illegal characters."})
+	// Check that new Username is valid (same rules as auth.RegisterUser)
+	if !utils.ContainsLettersOnly(json.Username) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Username contains illegal characters."})
+		return
+	}
+
+	username_lowered := strings.ToLower(json.Username)
+	if username_lowered == user.Username {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "That is already your username."})
+		return
+	}
+
+	// Confirm that the change is made by the account owner
+	if err := bcrypt.CompareHashAndPassword(user.Password, []byte(json.Password)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Wrong password. Please try again."})
 		return
 	}
 
-	// Update Bio and save
-	user.Bio = utils.TrimString(strings.TrimSpace(json.Bio), config.MAX_USER_BIO_LENGTH)
-	database.DB.Save(&user)
+	// Check that User has not changed their username recently
+	lastChange := lastUsernameChange(&user)
+	if lastChange.ID != 0 && time.Now().Before(lastChange.CreatedAt.Add(config.USERNAME_CHANGE_COOLDOWN)) {
+		c.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("You can only change your username once every %d days.", int(config.USERNAME_CHANGE_COOLDOWN.Hours()/24))})
+		return
+	}
 
-	fmt.Printf("Updated %s`s bio.\n\tBio: %s\n", user.Username, user.Bio)
+	// Check that Username is not taken (deleted Users keep theirs until purged) or held for another User
+	var takenCount int64
+	database.DB.Unscoped().Model(&models.User{}).Where("username = ?", username_lowered).Count(&takenCount)
+	if takenCount > 0 || auth.IsUsernameHeld(username_lowered, user.ID) {
+		c.JSON(http.StatusConflict, gin.H{"message": "Username is already taken."})
+		return
+	}
+
+	previousUsername := user.Username
+	if err := changeUsername(&user, username_lowered); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"message": "Username is already taken."})
+		return
+	}
+
+	// Tokens identify the User by ID and stay valid, a new one is issued in case the current one is a legacy token
+	jwt, err := utils.GenerateJWT(user.ID)
+	if err != nil {
+		c.JSON(http.StatusExpectationFailed, gin.H{"message": "Failed to create access token."})
+		return
+	}
+
+	fmt.Printf("%s has changed their username to %s.\n", previousUsername, user.Username)
+
+	c.JSON(http.StatusAccepted, auth.CreateAuthResponseWithJWT(jwt, &user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                 UpdateProfile | route: /users/updateprofile                */
+/* -------------------------------------------------------------------------- */
+// Omitted fields are left unchanged, empty optional fields (and a Year of 0) are cleared
+type UpdateProfileRequest struct {
+	Bio         *string               `json:"bio"`
+	DisplayName *string               `json:"displayName"`
+	Pronouns    *string               `json:"pronouns"`
+	Faculty     *string               `json:"faculty"`
+	Major       *string               `json:"major"`
+	Year        *uint                 `json:"year"`
+	Links       *[]ProfileLinkRequest `json:"links"`
+}
+
+func UpdateProfile(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json UpdateProfileRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Check that the new profile fields are valid
+	changes := map[string]interface{}{}
+	var err error
+	if json.Bio != nil {
+		if user.Bio, err = utils.ValidateText("Bio", *json.Bio, config.MAX_USER_BIO_LENGTH); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+			return
+		}
+		changes["bio"] = user.Bio
+	}
+
+	if json.DisplayName != nil {
+		if user.DisplayName, err = validateOptionalLine("Display name", *json.DisplayName, config.MAX_USER_DISPLAY_NAME_LENGTH); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+			return
+		}
+		changes["display_name"] = user.DisplayName
+	}
+
+	if json.Pronouns != nil {
+		if user.Pronouns, err = validateOptionalLine("Pronouns", *json.Pronouns, config.MAX_USER_PRONOUNS_LENGTH); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+			return
+		}
+		changes["pronouns"] = user.Pronouns
+	}
+
+	if json.Faculty != nil {
+		if user.Faculty, err = validateOptionalLine("Faculty", *json.Faculty, config.MAX_USER_FACULTY_LENGTH); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+			return
+		}
+		changes["faculty"] = user.Faculty
+	}
+
+	if json.Major != nil {
+		if user.Major, err = validateOptionalLine("Major", *json.Major, config.MAX_USER_FACULTY_LENGTH); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+			return
+		}
+		changes["major"] = user.Major
+	}
+
+	if json.Year != nil {
+		if *json.Year > config.MAX_USER_YEAR {
+			c.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("Year cannot be more than %d.", config.MAX_USER_YEAR)})
+			return
+		}
+		user.Year = *json.Year
+		changes["year"] = user.Year
+	}
+
+	var links []models.ProfileLink
+	if json.Links != nil {
+		if links, err = validateProfileLinks(&user, *json.Links); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+			return
+		}
+	}
+
+	if len(changes) == 0 && json.Links == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "No profile changes given."})
+		return
+	}
+
+	// Update changed columns and replace ProfileLinks
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if len(changes) > 0 {
+			if err := tx.Model(&user).Updates(changes).Error; err != nil {
+				return err
+			}
+		}
+
+		if json.Links != nil {
+			if err := tx.Where("user_id = ?", user.ID).Delete(&models.ProfileLink{}).Error; err != nil {
+				return err
+			}
+			if len(links) > 0 {
+				return tx.Create(&links).Error
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to update profile. Try again later."})
+		return
+	}
+
+	fmt.Printf("Updated %s`s profile.\n", user.Username)
+
+	c.JSON(http.StatusAccepted, CreateUserResponse(&user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                     UploadAvatar | route: /users/avatar                    */
+/* -------------------------------------------------------------------------- */
+func UploadAvatar(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Resize and save the new avatar to storage
+	avatarKey, uploaded := uploadAvatar(c)
+	if uploaded == false {
+		return
+	}
+
+	// Replace the avatar, the previous file is no longer needed
+	previousAvatar := models.User{AvatarKey: user.AvatarKey}
+	if err := database.DB.Model(&user).UpdateColumn("avatar_key", avatarKey).Error; err != nil {
+		storage.Store.Delete(avatarKey)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Unable to upload avatar. Try again later."})
+		return
+	}
+	deleteAvatar(&previousAvatar)
+	user.AvatarKey = avatarKey
+
+	fmt.Printf("%s has uploaded a new avatar.\n", user.Username)
+
+	c.JSON(http.StatusAccepted, CreateUserResponse(&user))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                  RemoveAvatar | route: /users/removeavatar                 */
+/* -------------------------------------------------------------------------- */
+func RemoveAvatar(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	if user.AvatarKey == "" {
+		c.JSON(http.StatusNotFound, gin.H{"message": "You have no avatar."})
+		return
+	}
+
+	database.DB.Model(&user).UpdateColumn("avatar_key", "")
+	deleteAvatar(&user)
+	user.AvatarKey = ""
+
+	fmt.Printf("%s has removed their avatar.\n", user.Username)
 
 	c.JSON(http.StatusAccepted, CreateUserResponse(&user))
 }
@@ -92,19 +351,519 @@ func DeleteUser(c *gin.Context) {
 		return
 	}
 
-	// Delete all of users Comments (to update existing posts commentsCount correctly)
-	// Deletion of user Posts will be handled by CascadeDelete
-	var comments []models.Comment
-	database.DB.Table("comments").Where("user_id = ?", user.ID).Find(&comments)
-	for _, comment := range comments {
-		database.DB.Delete(&comment)
+	// Soft delete the RequestUser, it can be restored by an admin until it is purged
+	database.DB.Delete(&user)
+
+	// Soft delete all of users Posts and Comments along with them.
+	// Comments are deleted one by one to update existing posts commentsCount correctly.
+	var userPosts []models.Post
+	database.DB.Where("user_id = ?", user.ID).Find(&userPosts)
+	for _, post := range userPosts {
+		posts.RemovePost(&post, &user)
 	}
 
-	// Delete the RequestUser from database
-	database.DB.Delete(&user)
+	var userComments []models.Comment
+	database.DB.Where("user_id = ?", user.ID).Find(&userComments)
+	for _, comment := range userComments {
+		comments.RemoveComment(&comment, &user)
+	}
 
 	fmt.Printf("Deleted user: %s.\n", user.Username)
 
 	// Success, user deleted
 	c.JSON(http.StatusAccepted, CreateUserResponse(&user))
 }
+
+/* -------------------------------------------------------------------------- */
+/*                    RestoreUser | route : /users/restore                    */
+/* -------------------------------------------------------------------------- */
+type RestoreUserRequest struct {
+	UserID uint `json:"userId" binding:"required"`
+}
+
+func RestoreUser(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json RestoreUserRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Check User is admin
+	if user.Role != config.USER_ROLE_ADMIN {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You do not have valid permissions."})
+		return
+	}
+
+	// Find deleted User from UserID
+	var targetUser models.User
+	database.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&targetUser, json.UserID)
+	if targetUser.ID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Deleted user not found."})
+		return
+	}
+
+	// Restore User along with the Posts and Comments that were deleted with them
+	restoreUser(&targetUser)
+
+	fmt.Printf("%s has restored user: %s.\n", user.Username, targetUser.Username)
+
+	// Return restored User data
+	c.JSON(http.StatusAccepted, CreateUserResponse(&targetUser))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                      FollowUser | route: /users/follow                     */
+/* -------------------------------------------------------------------------- */
+type FollowUserRequest struct {
+	UserID uint `json:"userId" binding:"required"`
+}
+
+func FollowUser(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json FollowUserRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find User to follow
+	targetUser, found := auth.FindUserFromID(c, json.UserID)
+	if found == false {
+		return
+	}
+
+	if targetUser.ID == user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You cannot follow yourself."})
+		return
+	}
+	if targetUser.Private {
+		c.JSON(http.StatusForbidden, gin.H{"message": "This user is private."})
+		return
+	}
+	if auth.IsBlocked(user.ID, targetUser.ID) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You cannot follow this user."})
+		return
+	}
+
+	// Create Follow and increment both counters together
+	var alreadyFollowing bool
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var existingFollow models.Follow
+		tx.Where("follower_id = ? AND user_id = ?", user.ID, targetUser.ID).Limit(1).Find(&existingFollow)
+		if existingFollow.ID != 0 {
+			alreadyFollowing = true
+			return nil
+		}
+
+		// A concurrent Follow of the same User fails on the unique index
+		follow := models.Follow{FollowerID: user.ID, UserID: targetUser.ID}
+		if err := tx.Create(&follow).Error; err != nil {
+			if database.IsDuplicateKeyError(err) {
+				alreadyFollowing = true
+				return nil
+			}
+			return err
+		}
+		if err := tx.Model(&user).UpdateColumn("following_count", gorm.Expr("following_count + ?", 1)).Error; err != nil {
+			return err
+		}
+		return tx.Model(&targetUser).UpdateColumn("followers_count", gorm.Expr("followers_count + ?", 1)).Error
+	})
+
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to follow user. Try again later."})
+		return
+	}
+	if alreadyFollowing {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You are already following this user."})
+		return
+	}
+
+	notifications.Notify(targetUser.ID, &user, config.NOTIFICATION_FOLLOW, 0, nil, false)
+
+	fmt.Printf("%s has followed %s.\n", user.Username, targetUser.Username)
+
+	// Return updated followed User
+	database.DB.First(&targetUser, targetUser.ID)
+	c.JSON(http.StatusAccepted, CreateUserResponse(&targetUser))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                UnfollowUser | route: /users/unfollow/:userId               */
+/* -------------------------------------------------------------------------- */
+type UnfollowUserRequest struct {
+	UserID uint `uri:"userId" binding:"required"`
+}
+
+func UnfollowUser(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json UnfollowUserRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find User to unfollow
+	targetUser, found := auth.FindUserFromID(c, json.UserID)
+	if found == false {
+		return
+	}
+
+	// Delete Follow and decrement both counters together
+	var notFollowing bool
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("follower_id = ? AND user_id = ?", user.ID, targetUser.ID).Delete(&models.Follow{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			notFollowing = true
+			return nil
+		}
+		if err := tx.Model(&user).Where("following_count > 0").UpdateColumn("following_count", gorm.Expr("following_count - ?", 1)).Error; err != nil {
+			return err
+		}
+		return tx.Model(&targetUser).Where("followers_count > 0").UpdateColumn("followers_count", gorm.Expr("followers_count - ?", 1)).Error
+	})
+
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to unfollow user. Try again later."})
+		return
+	}
+	if notFollowing {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You are not following this user."})
+		return
+	}
+
+	fmt.Printf("%s has unfollowed %s.\n", user.Username, targetUser.Username)
+
+	// Return updated unfollowed User
+	database.DB.First(&targetUser, targetUser.ID)
+	c.JSON(http.StatusAccepted, CreateUserResponse(&targetUser))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                          GetFollowers | route: ...                         */
+/* -------------------------------------------------------------------------- */
+// route: /users/followers/:userId/:perPage/:pageNumber
+type GetFollowsRequest struct {
+	UserID     uint `uri:"userId" binding:"required"`
+	PerPage    uint `uri:"perPage" binding:"required"`
+	PageNumber uint `uri:"pageNumber" binding:"required"`
+}
+
+func GetFollowers(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetFollowsRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	targetUser, found := findVisibleUser(c, &user, json.UserID)
+	if found == false {
+		return
+	}
+
+	// Return fetched followers (except the ones blocked either way)
+	c.JSON(http.StatusAccepted, GetRelatedUsersFromContext(filterBlockedUsers(followersContext(targetUser.ID), &user), json.PerPage, json.PageNumber))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                          GetFollowing | route: ...                         */
+/* -------------------------------------------------------------------------- */
+// route: /users/following/:userId/:perPage/:pageNumber
+func GetFollowing(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetFollowsRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	targetUser, found := findVisibleUser(c, &user, json.UserID)
+	if found == false {
+		return
+	}
+
+	// Return fetched followed Users (except the ones blocked either way)
+	c.JSON(http.StatusAccepted, GetRelatedUsersFromContext(filterBlockedUsers(followingContext(targetUser.ID), &user), json.PerPage, json.PageNumber))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                       BlockUser | route: /users/block                      */
+/* -------------------------------------------------------------------------- */
+type BlockUserRequest struct {
+	UserID uint `json:"userId" binding:"required"`
+}
+
+func BlockUser(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json BlockUserRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find User to block
+	targetUser, found := auth.FindUserFromID(c, json.UserID)
+	if found == false {
+		return
+	}
+
+	if targetUser.ID == user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You cannot block yourself."})
+		return
+	}
+
+	// Create Block and remove the Follows between both Users together
+	var alreadyBlocked bool
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var existingBlock models.Block
+		tx.Where("blocker_id = ? AND user_id = ?", user.ID, targetUser.ID).Limit(1).Find(&existingBlock)
+		if existingBlock.ID != 0 {
+			alreadyBlocked = true
+			return nil
+		}
+
+		// A concurrent Block of the same User fails on the unique index
+		block := models.Block{BlockerID: user.ID, UserID: targetUser.ID}
+		if err := tx.Create(&block).Error; err != nil {
+			if database.IsDuplicateKeyError(err) {
+				alreadyBlocked = true
+				return nil
+			}
+			return err
+		}
+		return removeFollowsBetween(tx, &user, &targetUser)
+	})
+
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to block user. Try again later."})
+		return
+	}
+	if alreadyBlocked {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You have already blocked this user."})
+		return
+	}
+
+	fmt.Printf("%s has blocked %s.\n", user.Username, targetUser.Username)
+
+	// Return updated blocked User
+	database.DB.First(&targetUser, targetUser.ID)
+	c.JSON(http.StatusAccepted, CreateUserResponse(&targetUser))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                 UnblockUser | route: /users/unblock/:userId                */
+/* -------------------------------------------------------------------------- */
+type UnblockUserRequest struct {
+	UserID uint `uri:"userId" binding:"required"`
+}
+
+func UnblockUser(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json UnblockUserRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find User to unblock
+	targetUser, found := auth.FindUserFromID(c, json.UserID)
+	if found == false {
+		return
+	}
+
+	// Follows removed by the Block are not restored
+	result := database.DB.Where("blocker_id = ? AND user_id = ?", user.ID, targetUser.ID).Delete(&models.Block{})
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You have not blocked this user."})
+		return
+	}
+
+	fmt.Printf("%s has unblocked %s.\n", user.Username, targetUser.Username)
+
+	c.JSON(http.StatusAccepted, CreateUserResponse(&targetUser))
+}
+
+/* -------------------------------------------------------------------------- */
+/*        GetBlockedUsers | route: /users/blocked/:perPage/:pageNumber        */
+/* -------------------------------------------------------------------------- */
+type GetRelatedUsersRequest struct {
+	PerPage    uint `uri:"perPage" binding:"required"`
+	PageNumber uint `uri:"pageNumber" binding:"required"`
+}
+
+func GetBlockedUsers(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetRelatedUsersRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Return fetched blocked Users
+	c.JSON(http.StatusAccepted, GetRelatedUsersFromContext(blockedContext(user.ID), json.PerPage, json.PageNumber))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                        MuteUser | route: /users/mute                       */
+/* -------------------------------------------------------------------------- */
+type MuteUserRequest struct {
+	UserID uint `json:"userId" binding:"required"`
+}
+
+func MuteUser(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json MuteUserRequest
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find User to mute
+	targetUser, found := auth.FindUserFromID(c, json.UserID)
+	if found == false {
+		return
+	}
+
+	if targetUser.ID == user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You cannot mute yourself."})
+		return
+	}
+
+	if auth.IsMuted(user.ID, targetUser.ID) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You have already muted this user."})
+		return
+	}
+
+	// Muted User is not told about the Mute.
+	// A concurrent Mute of the same User fails on the unique index.
+	if err := database.DB.Create(&models.Mute{MuterID: user.ID, UserID: targetUser.ID}).Error; err != nil {
+		if database.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusForbidden, gin.H{"message": "You have already muted this user."})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to mute user. Try again later."})
+		return
+	}
+
+	fmt.Printf("%s has muted %s.\n", user.Username, targetUser.Username)
+
+	c.JSON(http.StatusAccepted, CreateUserResponse(&targetUser))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                  UnmuteUser | route: /users/unmute/:userId                 */
+/* -------------------------------------------------------------------------- */
+type UnmuteUserRequest struct {
+	UserID uint `uri:"userId" binding:"required"`
+}
+
+func UnmuteUser(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json UnmuteUserRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Find User to unmute
+	targetUser, found := auth.FindUserFromID(c, json.UserID)
+	if found == false {
+		return
+	}
+
+	result := database.DB.Where("muter_id = ? AND user_id = ?", user.ID, targetUser.ID).Delete(&models.Mute{})
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You have not muted this user."})
+		return
+	}
+
+	fmt.Printf("%s has unmuted %s.\n", user.Username, targetUser.Username)
+
+	c.JSON(http.StatusAccepted, CreateUserResponse(&targetUser))
+}
+
+/* -------------------------------------------------------------------------- */
+/*          GetMutedUsers | route: /users/muted/:perPage/:pageNumber          */
+/* -------------------------------------------------------------------------- */
+func GetMutedUsers(c *gin.Context) {
+	// Check that RequestUser is authenticated
+	user, found := auth.VerifyAuth(c)
+	if found == false {
+		return
+	}
+
+	// Parse RequestBody
+	var json GetRelatedUsersRequest
+	if err := c.ShouldBindUri(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// Return fetched muted Users
+	c.JSON(http.StatusAccepted, GetRelatedUsersFromContext(mutedContext(user.ID), json.PerPage, json.PageNumber))
+}
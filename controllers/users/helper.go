@@ -1,25 +1,498 @@
 package users
 
-import "github.com/mfjkri/OneNUS-Backend/models"
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/mfjkri/OneNUS-Backend/config"
+	"github.com/mfjkri/OneNUS-Backend/controllers/attachments"
+	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/controllers/comments"
+	"github.com/mfjkri/OneNUS-Backend/controllers/posts"
+	"github.com/mfjkri/OneNUS-Backend/database"
+	"github.com/mfjkri/OneNUS-Backend/models"
+	"github.com/mfjkri/OneNUS-Backend/storage"
+	"github.com/mfjkri/OneNUS-Backend/utils"
+	"gorm.io/gorm"
+)
+
+type ProfileLinkResponse struct {
+	Label string `json:"label" binding:"required"`
+	URL   string `json:"url" binding:"required"`
+}
 
 type UserResponse struct {
-	ID            uint   `json:"id" binding:"required"`
-	Username      string `json:"username" binding:"required"`
-	Role          string `json:"role" binding:"required"`
-	Bio           string `json:"bio" binding:"required"`
-	PostsCount    uint   `json:"postsCount" binding:"required"`
-	CommentsCount uint   `json:"commentsCount" binding:"required"`
-	CreatedAt     int64  `json:"createdAt" binding:"required"`
+	ID             uint                  `json:"id" binding:"required"`
+	Username       string                `json:"username" binding:"required"`
+	Role           string                `json:"role" binding:"required"`
+	Bio            string                `json:"bio" binding:"required"`
+	DisplayName    string                `json:"displayName"`
+	Pronouns       string                `json:"pronouns"`
+	Faculty        string                `json:"faculty"`
+	Major          string                `json:"major"`
+	Year           uint                  `json:"year"`
+	AvatarURL      string                `json:"avatarUrl"`
+	Links          []ProfileLinkResponse `json:"links" binding:"required"`
+	PostsCount     uint                  `json:"postsCount" binding:"required"`
+	CommentsCount  uint                  `json:"commentsCount" binding:"required"`
+	FollowersCount uint                  `json:"followersCount" binding:"required"`
+	FollowingCount uint                  `json:"followingCount" binding:"required"`
+	CreatedAt      int64                 `json:"createdAt" binding:"required"`
 }
 
-func CreateUserResponse(user *models.User) UserResponse {
+func createUserResponse(user *models.User, links []models.ProfileLink) UserResponse {
+	linksResponse := []ProfileLinkResponse{}
+	for _, link := range links {
+		linksResponse = append(linksResponse, ProfileLinkResponse{Label: link.Label, URL: link.URL})
+	}
+
+	var avatarURL string
+	if user.AvatarKey != "" {
+		avatarURL, _ = storage.Store.SignedURL(user.AvatarKey, config.ATTACHMENT_URL_EXPIRY)
+	}
+
 	return UserResponse{
-		ID:            user.ID,
-		Username:      user.Username,
-		Role:          user.Role,
-		Bio:           user.Bio,
-		PostsCount:    user.PostsCount,
-		CommentsCount: user.CommentsCount,
-		CreatedAt:     user.CreatedAt.Unix(),
+		ID:             user.ID,
+		Username:       user.Username,
+		Role:           user.Role,
+		Bio:            user.Bio,
+		DisplayName:    user.DisplayName,
+		Pronouns:       user.Pronouns,
+		Faculty:        user.Faculty,
+		Major:          user.Major,
+		Year:           user.Year,
+		AvatarURL:      avatarURL,
+		Links:          linksResponse,
+		PostsCount:     user.PostsCount,
+		CommentsCount:  user.CommentsCount,
+		FollowersCount: user.FollowersCount,
+		FollowingCount: user.FollowingCount,
+		CreatedAt:      user.CreatedAt.Unix(),
+	}
+}
+
+// Convert a User Model (and its ProfileLinks) into a JSON format
+func CreateUserResponse(user *models.User) UserResponse {
+	var links []models.ProfileLink
+	database.DB.Where("user_id = ?", user.ID).Order("position").Find(&links)
+	return createUserResponse(user, links)
+}
+
+// Same as CreateUserResponse for multiple Users, fetching all their ProfileLinks at once
+func CreateUsersResponse(users []models.User) []UserResponse {
+	usersResponse := []UserResponse{}
+	if len(users) == 0 {
+		return usersResponse
+	}
+
+	var userIDs []uint
+	for _, user := range users {
+		userIDs = append(userIDs, user.ID)
+	}
+
+	var links []models.ProfileLink
+	database.DB.Where("user_id IN ?", userIDs).Order("position").Find(&links)
+	userLinks := map[uint][]models.ProfileLink{}
+	for _, link := range links {
+		userLinks[link.UserID] = append(userLinks[link.UserID], link)
+	}
+
+	for _, user := range users {
+		usersResponse = append(usersResponse, createUserResponse(&user, userLinks[user.ID]))
+	}
+	return usersResponse
+}
+
+type GetUsersResponse struct {
+	Users      []UserResponse `json:"users" binding:"required"`
+	UsersCount int64          `json:"usersCount" binding:"required"`
+}
+
+/* -------------------------------------------------------------------------- */
+/*                                   Profile                                  */
+/* -------------------------------------------------------------------------- */
+// Normalizes and validates an optional single-line profile field, empty clears the field
+func validateOptionalLine(field string, s string, maxLen int) (string, error) {
+	if utils.NormalizeText(s) == "" {
+		return "", nil
+	}
+	return utils.ValidateLine(field, s, maxLen)
+}
+
+type ProfileLinkRequest struct {
+	Label string `json:"label" binding:"required"`
+	URL   string `json:"url" binding:"required"`
+}
+
+// Validates the requested profile links and converts them into ProfileLink models (not yet saved)
+func validateProfileLinks(user *models.User, linksRequest []ProfileLinkRequest) ([]models.ProfileLink, error) {
+	if len(linksRequest) > config.MAX_PROFILE_LINKS {
+		return nil, fmt.Errorf("Too many profile links (max %d).", config.MAX_PROFILE_LINKS)
+	}
+
+	links := []models.ProfileLink{}
+	for position, linkRequest := range linksRequest {
+		label, err := utils.ValidateLine("Link label", linkRequest.Label, config.MAX_PROFILE_LINK_LABEL_LENGTH)
+		if err != nil {
+			return nil, err
+		}
+
+		rawURL, err := utils.ValidateLine("Link URL", linkRequest.URL, config.MAX_PROFILE_LINK_URL_LENGTH)
+		if err != nil {
+			return nil, err
+		}
+
+		// Only absolute web links, anything else (e.g. javascript:) could run in the frontend
+		parsedURL, err := url.Parse(rawURL)
+		if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+			return nil, fmt.Errorf("Link URL must be a http(s) link.")
+		}
+
+		links = append(links, models.ProfileLink{
+			UserID:   user.ID,
+			Label:    label,
+			URL:      parsedURL.String(),
+			Position: uint(position),
+		})
+	}
+	return links, nil
+}
+
+// Reads the "file" field of a multipart upload, resizes it and saves it to storage as an avatar.
+// Returns the storage key of the avatar, the User is not yet updated.
+func uploadAvatar(c *gin.Context) (string, bool) {
+	// Hard limit on the request body so oversized uploads are never fully read
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.MAX_AVATAR_SIZE+(1<<20))
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "No file uploaded or file is too large."})
+		return "", false
+	}
+
+	if fileHeader.Size > config.MAX_AVATAR_SIZE {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": fmt.Sprintf("File is too large (max %d MB).", config.MAX_AVATAR_SIZE>>20)})
+		return "", false
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Unable to read uploaded file."})
+		return "", false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, config.MAX_AVATAR_SIZE))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Unable to read uploaded file."})
+		return "", false
+	}
+
+	// Sniff content type from the file contents, only images can be avatars
+	contentType := strings.Split(http.DetectContentType(data), ";")[0]
+	if _, allowed := config.ALLOWED_ATTACHMENT_TYPES[contentType]; allowed == false || !strings.HasPrefix(contentType, "image/") {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"message": "Avatar must be an image."})
+		return "", false
+	}
+
+	imageConfig, _, err := utils.DecodeImageConfig(data)
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"message": "Image could not be read."})
+		return "", false
+	}
+
+	if imageConfig.Width > config.MAX_IMAGE_DIMENSION || imageConfig.Height > config.MAX_IMAGE_DIMENSION {
+		c.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("Image is too large (max %dx%d pixels).", config.MAX_IMAGE_DIMENSION, config.MAX_IMAGE_DIMENSION)})
+		return "", false
+	}
+
+	avatar, avatarType, err := utils.ResizeImage(data, config.AVATAR_SIZE)
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"message": "Image could not be read."})
+		return "", false
+	}
+
+	// Every upload gets a new key so cached URLs of the previous avatar are never served the new one
+	key := "avatars/" + uuid.NewString() + config.ALLOWED_ATTACHMENT_TYPES[avatarType]
+	if err := storage.Store.Put(key, bytes.NewReader(avatar), int64(len(avatar)), avatarType); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Unable to upload avatar. Try again later."})
+		return "", false
+	}
+
+	return key, true
+}
+
+// Removes the avatar file of a User from storage
+func deleteAvatar(user *models.User) {
+	if user.AvatarKey != "" {
+		storage.Store.Delete(user.AvatarKey)
+	}
+}
+
+/* -------------------------------------------------------------------------- */
+/*                                  Usernames                                 */
+/* -------------------------------------------------------------------------- */
+// Finds a User from their current username, or from a username they have changed away from
+func findUserFromUsername(c *gin.Context, username string) (models.User, bool) {
+	username = strings.ToLower(username)
+
+	var targetUser models.User
+	database.DB.Where("username = ?", username).First(&targetUser)
+
+	// Redirect old usernames to the User that had them last
+	if targetUser.ID == 0 {
+		var usernameChange models.UsernameChange
+		database.DB.Where("old_username = ?", username).Order("created_at DESC, id DESC").First(&usernameChange)
+		if usernameChange.ID != 0 {
+			database.DB.First(&targetUser, usernameChange.UserID)
+		}
+	}
+
+	if targetUser.ID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "User not found."})
+		return targetUser, false
+	}
+	return targetUser, true
+}
+
+// Most recent username change of a User (ID is 0 if they never changed it)
+func lastUsernameChange(user *models.User) models.UsernameChange {
+	var usernameChange models.UsernameChange
+	database.DB.Where("user_id = ?", user.ID).Order("created_at DESC, id DESC").First(&usernameChange)
+	return usernameChange
+}
+
+// Changes the username of a User along with the author name copied onto their Posts and Comments
+func changeUsername(user *models.User, username string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		usernameChange := models.UsernameChange{
+			UserID:      user.ID,
+			OldUsername: user.Username,
+			NewUsername: username,
+		}
+		if err := tx.Create(&usernameChange).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(user).Update("username", username).Error; err != nil {
+			return err
+		}
+
+		// Deleted Posts and Comments are renamed too, they can still be restored
+		if err := tx.Unscoped().Model(&models.Post{}).Where("user_id = ?", user.ID).UpdateColumn("author", username).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.Comment{}).Where("user_id = ?", user.ID).UpdateColumn("author", username).Error
+	})
+}
+
+/* -------------------------------------------------------------------------- */
+/*                                   Follows                                  */
+/* -------------------------------------------------------------------------- */
+// Users that follow userID, most recent follow first
+func followersContext(userID uint) *gorm.DB {
+	return database.DB.Model(&models.User{}).Joins("JOIN follows AS relations ON relations.follower_id = users.id AND relations.user_id = ?", userID)
+}
+
+// Users that userID follows, most recent follow first
+func followingContext(userID uint) *gorm.DB {
+	return database.DB.Model(&models.User{}).Joins("JOIN follows AS relations ON relations.user_id = users.id AND relations.follower_id = ?", userID)
+}
+
+// Leaves Users that user has blocked or that have blocked user out of a listing of Users
+func filterBlockedUsers(dbContext *gorm.DB, user *models.User) *gorm.DB {
+	return dbContext.Where("users.id NOT IN (?)", auth.BlockedUsersQuery(user.ID))
+}
+
+// Finds a User that user can see, Users blocked either way are not found
+func findVisibleUser(c *gin.Context, user *models.User, userID uint) (models.User, bool) {
+	targetUser, found := auth.FindUserFromID(c, userID)
+	if found == false {
+		return targetUser, false
+	}
+	if auth.IsBlocked(user.ID, targetUser.ID) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "User not found."})
+		return targetUser, false
+	}
+	return targetUser, true
+}
+
+// Fetches a page of Users from a context joined with their Follows, Blocks or Mutes AS relations
+// (e.g. followersContext or blockedContext), most recent relation first
+func GetRelatedUsersFromContext(dbContext *gorm.DB, perPage uint, pageNumber uint) GetUsersResponse {
+	var users []models.User
+
+	// Limit PerPage to config.MAX_PER_PAGE
+	clampedPerPage := int64(math.Min(config.MAX_PER_PAGE, float64(perPage)))
+	offsetUsersCount := int64(pageNumber-1) * clampedPerPage
+
+	// Get total count for Users
+	var totalUsersCount int64
+	dbContext.Count(&totalUsersCount)
+
+	usersResponse := []UserResponse{}
+
+	// If we are request beyond the bounds of total count, return nothing
+	if (offsetUsersCount < 0) || (offsetUsersCount > totalUsersCount) {
+		return GetUsersResponse{Users: usersResponse, UsersCount: 0}
+	}
+
+	dbContext.Select("users.*").Order("relations.created_at DESC, relations.id DESC").Limit(int(clampedPerPage)).Offset(int(offsetUsersCount)).Find(&users)
+
+	return GetUsersResponse{
+		Users:      CreateUsersResponse(users),
+		UsersCount: totalUsersCount,
+	}
+}
+
+// Removes the Follows of a User that is about to be purged and
+// decrements the counters of the Users on the other side of them
+func removeFollows(user *models.User) {
+	database.DB.Model(&models.User{}).Unscoped().Where("id IN (?) AND following_count > 0", database.DB.Model(&models.Follow{}).Select("follower_id").Where("user_id = ?", user.ID)).UpdateColumn("following_count", gorm.Expr("following_count - ?", 1))
+	database.DB.Model(&models.User{}).Unscoped().Where("id IN (?) AND followers_count > 0", database.DB.Model(&models.Follow{}).Select("user_id").Where("follower_id = ?", user.ID)).UpdateColumn("followers_count", gorm.Expr("followers_count - ?", 1))
+	database.DB.Where("user_id = ? OR follower_id = ?", user.ID, user.ID).Delete(&models.Follow{})
+}
+
+// Restores a soft deleted User along with the Posts and Comments that were deleted with them.
+// Content the User had deleted themselves beforehand stays deleted.
+func restoreUser(user *models.User) {
+	deletedAt := user.DeletedAt.Time
+	restoredColumns := map[string]interface{}{
+		"deleted_at":    nil,
+		"deleted_by_id": 0,
+	}
+
+	database.DB.Unscoped().Model(user).UpdateColumn("deleted_at", nil)
+	user.DeletedAt = gorm.DeletedAt{}
+
+	deletedWithUser := "user_id = ? AND deleted_by_id = ? AND deleted_at >= ?"
+	database.DB.Unscoped().Model(&models.Post{}).Where(deletedWithUser, user.ID, user.ID, deletedAt).UpdateColumns(restoredColumns)
+
+	// Restored Comments change the metadata of the Posts they belong to
+	type restoredCount struct {
+		PostID uint
+		Count  int
+	}
+	var restoredCounts []restoredCount
+	database.DB.Unscoped().Model(&models.Comment{}).Select("post_id, COUNT(*) AS count").Where(deletedWithUser, user.ID, user.ID, deletedAt).Group("post_id").Scan(&restoredCounts)
+	database.DB.Unscoped().Model(&models.Comment{}).Where(deletedWithUser, user.ID, user.ID, deletedAt).UpdateColumns(restoredColumns)
+	for _, restored := range restoredCounts {
+		models.UpdatePostCommentsMetadata(database.DB, restored.PostID, restored.Count)
+	}
+
+	// Recount PostsCount and CommentsCount of User
+	var totalPostsCount int64
+	database.DB.Model(&models.Post{}).Where("user_id = ? AND status = ?", user.ID, config.POST_STATUS_PUBLISHED).Count(&totalPostsCount)
+	user.PostsCount = uint(totalPostsCount)
+
+	var totalCommentsCount int64
+	database.DB.Model(&models.Comment{}).Where("user_id = ?", user.ID).Count(&totalCommentsCount)
+	user.CommentsCount = uint(totalCommentsCount)
+
+	database.DB.Model(user).UpdateColumns(map[string]interface{}{
+		"posts_count":    user.PostsCount,
+		"comments_count": user.CommentsCount,
+	})
+}
+
+// Permanently deletes Users (and everything they created) that were soft deleted
+// more than config.TRASH_RETENTION_PERIOD ago
+func PurgeDeletedUsers() {
+	var users []models.User
+	database.DB.Unscoped().Where("deleted_at < ?", time.Now().Add(-config.TRASH_RETENTION_PERIOD)).Find(&users)
+
+	for _, user := range users {
+		var userPosts []models.Post
+		database.DB.Unscoped().Where("user_id = ?", user.ID).Find(&userPosts)
+		for _, post := range userPosts {
+			posts.PurgePost(&post)
+		}
+
+		// Remaining Attachments of User (on other Posts) and their other Comments are removed by CascadeDelete
+		attachments.DeleteAttachmentsFromContext(database.DB.Where("user_id = ?", user.ID))
+		removeFollows(&user)
+		deleteAvatar(&user)
+
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			// Comments of User that have replies are kept as [deleted] placeholders without an author,
+			// PurgeDeletedComments removes them once their replies are gone
+			if err := tx.Unscoped().Model(&models.Comment{}).Where("user_id = ? AND replies_count > 0", user.ID).UpdateColumns(map[string]interface{}{
+				"user_id":   nil,
+				"author":    comments.DeletedCommentText,
+				"text":      "",
+				"text_html": "",
+			}).Error; err != nil {
+				return err
+			}
+
+			// The parents of the Comments that are removed by CascadeDelete lose those replies
+			type removedReplies struct {
+				ParentID uint
+				Count    int
+			}
+			var removedRepliesCounts []removedReplies
+			if err := tx.Unscoped().Model(&models.Comment{}).Select("parent_id, COUNT(*) AS count").Where("user_id = ? AND parent_id IS NOT NULL", user.ID).Group("parent_id").Scan(&removedRepliesCounts).Error; err != nil {
+				return err
+			}
+			for _, removed := range removedRepliesCounts {
+				if err := tx.Unscoped().Model(&models.Comment{}).Where("id = ?", removed.ParentID).UpdateColumn("replies_count", gorm.Expr("GREATEST(replies_count, ?) - ?", removed.Count, removed.Count)).Error; err != nil {
+					return err
+				}
+			}
+
+			return tx.Unscoped().Delete(&user).Error
+		})
+		if err != nil {
+			fmt.Printf("Unable to purge user %s: %s\n", user.Username, err)
+		}
+	}
+
+	if len(users) > 0 {
+		fmt.Printf("Purged %d deleted users.\n", len(users))
+	}
+}
+
+/* -------------------------------------------------------------------------- */
+/*                              Blocks and Mutes                              */
+/* -------------------------------------------------------------------------- */
+// Users that userID has blocked, most recent block first
+func blockedContext(userID uint) *gorm.DB {
+	return database.DB.Model(&models.User{}).Joins("JOIN blocks AS relations ON relations.user_id = users.id AND relations.blocker_id = ?", userID)
+}
+
+// Users that userID has muted, most recent mute first
+func mutedContext(userID uint) *gorm.DB {
+	return database.DB.Model(&models.User{}).Joins("JOIN mutes AS relations ON relations.user_id = users.id AND relations.muter_id = ?", userID)
+}
+
+// Removes the Follows between two Users (either way) and decrements their counters.
+// Used when one of them blocks the other.
+func removeFollowsBetween(tx *gorm.DB, user *models.User, otherUser *models.User) error {
+	for _, pair := range [][2]*models.User{{user, otherUser}, {otherUser, user}} {
+		follower, followed := pair[0], pair[1]
+
+		result := tx.Where("follower_id = ? AND user_id = ?", follower.ID, followed.ID).Delete(&models.Follow{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ? AND following_count > 0", follower.ID).UpdateColumn("following_count", gorm.Expr("following_count - ?", 1)).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{}).Where("id = ? AND followers_count > 0", followed.ID).UpdateColumn("followers_count", gorm.Expr("followers_count - ?", 1)).Error; err != nil {
+			return err
+		}
 	}
+	return nil
 }
@@ -4,6 +4,21 @@ import "github.com/gin-gonic/gin"
 
 func RegisterRoutes(r *gin.Engine) {
 	r.GET("users/getbyid/:userId", GetUserFromID)
-	r.POST("users/updatebio", UpdateBio)
+	r.GET("users/getbyusername/:username", GetUserFromUsername)
+	r.POST("users/changeusername", ChangeUsername)
+	r.POST("users/updateprofile", UpdateProfile)
+	r.POST("users/avatar", UploadAvatar)
+	r.DELETE("users/removeavatar", RemoveAvatar)
 	r.DELETE("users/delete", DeleteUser)
+	r.POST("users/restore", RestoreUser)
+	r.POST("users/follow", FollowUser)
+	r.DELETE("users/unfollow/:userId", UnfollowUser)
+	r.GET("users/followers/:userId/:perPage/:pageNumber", GetFollowers)
+	r.GET("users/following/:userId/:perPage/:pageNumber", GetFollowing)
+	r.POST("users/block", BlockUser)
+	r.DELETE("users/unblock/:userId", UnblockUser)
+	r.GET("users/blocked/:perPage/:pageNumber", GetBlockedUsers)
+	r.POST("users/mute", MuteUser)
+	r.DELETE("users/unmute/:userId", UnmuteUser)
+	r.GET("users/muted/:perPage/:pageNumber", GetMutedUsers)
 }
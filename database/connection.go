@@ -3,13 +3,18 @@ package database
 import (
 	"fmt"
 	"os"
+	"time"
 
+	mysqlDriver "github.com/go-sql-driver/mysql"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
+// Time zone that DATETIME columns are stored in (loc of the DB connection string)
+var Location = time.UTC
+
 func Connect() {
 	connection, err := gorm.Open(mysql.Open(os.Getenv("DB")), &gorm.Config{})
 
@@ -20,4 +25,8 @@ func Connect() {
 	fmt.Println("Successfully connected to database...")
 
 	DB = connection
+
+	if dsnConfig, err := mysqlDriver.ParseDSN(os.Getenv("DB")); err == nil {
+		Location = dsnConfig.Loc
+	}
 }
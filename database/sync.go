@@ -7,7 +7,12 @@ import (
 )
 
 func Migrate() {
-	DB.AutoMigrate(&models.User{}, &models.Post{}, &models.Comment{})
+	DB.AutoMigrate(&models.User{}, &models.ProfileLink{}, &models.Post{}, &models.Comment{}, &models.Attachment{}, &models.Poll{}, &models.PollOption{}, &models.PollVote{}, &models.PostView{}, &models.Star{}, &models.Report{}, &models.Mention{}, &models.Notification{}, &models.Follow{}, &models.TagFollow{}, &models.Subscription{}, &models.Reaction{}, &models.UsernameChange{}, &models.Block{}, &models.Mute{}, &models.Conversation{}, &models.Participant{}, &models.Message{})
+
+	// Reports used to be unique per reporter and target, users can now report a target again once their report is closed
+	if DB.Migrator().HasIndex(&models.Report{}, "idx_report_reporter_target") {
+		DB.Migrator().DropIndex(&models.Report{}, "idx_report_reporter_target")
+	}
 
 	fmt.Println("Successfully migrated database...")
 }
@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"github.com/mfjkri/OneNUS-Backend/config"
+	"github.com/mfjkri/OneNUS-Backend/controllers/admin"
+	"github.com/mfjkri/OneNUS-Backend/controllers/comments"
+	"github.com/mfjkri/OneNUS-Backend/controllers/posts"
+	"github.com/mfjkri/OneNUS-Backend/controllers/users"
+	"github.com/mfjkri/OneNUS-Backend/utils"
+)
+
+func RegisterJobs() {
+	// Publishes scheduled posts that are due
+	utils.RunEvery(config.POST_PUBLISHER_INTERVAL, posts.PublishScheduledPosts)
+
+	// Saves buffered post views to the database
+	utils.RunEvery(config.POST_VIEWS_FLUSH_INTERVAL, posts.FlushPostViews)
+
+	// Permanently deletes soft deleted content past its retention period
+	utils.RunEvery(config.TRASH_PURGE_INTERVAL, func() {
+		comments.PurgeDeletedComments()
+		posts.PurgeDeletedPosts()
+		users.PurgeDeletedUsers()
+	})
+
+	// Fixes drifted counters of Users and Posts
+	utils.RunEvery(config.RECONCILE_INTERVAL, func() {
+		admin.ReconcileCounters()
+	})
+}
@@ -7,9 +7,12 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/mfjkri/OneNUS-Backend/controllers/admin"
 	"github.com/mfjkri/OneNUS-Backend/database"
+	"github.com/mfjkri/OneNUS-Backend/jobs"
 	"github.com/mfjkri/OneNUS-Backend/routes"
 	"github.com/mfjkri/OneNUS-Backend/seed"
+	"github.com/mfjkri/OneNUS-Backend/storage"
 	"github.com/mfjkri/OneNUS-Backend/utils"
 )
 
@@ -18,6 +21,7 @@ func init() {
 	utils.LoadEnv()
 	database.Connect()
 	database.Migrate()
+	storage.Connect()
 }
 
 func CORSConfig() cors.Config {
@@ -59,10 +63,15 @@ func main() {
 		seed.GenerateData()
 
 	} else if str_cmd == "update" {
-		seed.UpdateUsers()
-		seed.UpdatePosts()
+		fmt.Println("Reconciling users and posts counters...")
+		admin.ReconcileCounters()
+		fmt.Println("Reconcile users and posts counters complete!")
+		seed.RenderTexts()
 	}
 
+	// Start background jobs only once -cmd utilities are done with the database
+	jobs.RegisterJobs()
+
 	fmt.Println("Now listening on port", os.Getenv("PORT"), "...")
 	// Start listening
 	router.Run()
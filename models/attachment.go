@@ -0,0 +1,22 @@
+package models
+
+// File uploaded to a Post or a Comment.
+// The file itself (and its thumbnail if it is an image) lives in storage under Key.
+type Attachment struct {
+	BaseModel
+
+	FileName     string
+	ContentType  string
+	Size         int64
+	Key          string `gorm:"unique;size:191"`
+	ThumbnailKey string
+	Width        int
+	Height       int
+
+	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID uint
+
+	// Only one of PostID or CommentID is set
+	PostID    *uint
+	CommentID *uint
+}
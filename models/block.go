@@ -0,0 +1,14 @@
+package models
+
+// Blocked Users cannot see each other's content, reply to or mention each other, or follow each other
+type Block struct {
+	BaseModel
+
+	// User that blocks
+	Blocker   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	BlockerID uint `gorm:"uniqueIndex:idx_block_blocker_user"`
+
+	// User being blocked
+	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID uint `gorm:"uniqueIndex:idx_block_blocker_user;index"`
+}
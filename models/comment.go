@@ -9,47 +9,51 @@ import (
 type Comment struct {
 	BaseModel
 
-	Text string `json:"text"`
+	Text     string `json:"text"`
+	TextHTML string `json:"textHtml"`
 
 	Author string
 	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	UserID uint
 
+	// Author and UserID are only revealed to the author and moderators
+	Anonymous bool `gorm:"default:false"`
+
 	Post   Post `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	PostID uint
-}
 
-func (comment *Comment) AfterDelete(tx *gorm.DB) (err error) {
-	var post Post
-	tx.First(&post, comment.PostID)
-	if post.ID != 0 {
-		// Update associated Post metadata to reflect new changes
-		postUpdatedAt := post.UpdatedAt
+	// Replies point to the Comment they reply to (nil for top level Comments).
+	// There is no foreign key so that replies are preserved when their parent is deleted.
+	ParentID     *uint `gorm:"index"`
+	Depth        uint  `gorm:"default:0"`
+	RepliesCount uint  `gorm:"default:0"`
 
-		post.CommentsCount -= 1
+	// Score is the Wilson lower bound of the votes, used by the "top" sort
+	UpvotesCount   uint    `gorm:"default:0"`
+	DownvotesCount uint    `gorm:"default:0"`
+	Score          float64 `gorm:"default:0;index"`
 
-		var lastComment Comment
-		tx.Table("comments").Where("post_id = ?", post.ID).Order("created_at DESC, id DESC").First(&lastComment)
-		if lastComment.ID != 0 {
-			post.CommentedAt = lastComment.CreatedAt
-		} else {
-			post.CommentedAt = time.Unix(0, 0)
-		}
+	Attachments []Attachment `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 
-		tx.Save(&post)
+	// Hidden from other Users after config.REPORTS_TO_HIDE reports (until dismissed)
+	Hidden bool `gorm:"default:false"`
 
-		// By default post.UpdatedAt will get updated by these changes but we don't want that.
-		// UpdatedAt should only reflect changes to post.Text
-		tx.Model(&post).Update("updated_at", postUpdatedAt)
-	}
+	// Soft deleted Comments are shown as placeholders and can be restored until they are purged
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	DeletedByID uint
+}
 
-	// var user User
-	// tx.First(&user, comment.UserID)
-	// if user.ID != 0 {
-	// 	// Decrement user comments count
-	// 	user.CommentsCount -= 1
-	// 	tx.Save(&user)
-	// }
+// Adds delta to CommentsCount of a Post and recomputes CommentedAt from its remaining Comments.
+// Both are computed by the database so that Comments created or deleted concurrently are not lost.
+// UpdatedAt of the Post is left untouched, it should only reflect changes to post.Text
+func UpdatePostCommentsMetadata(tx *gorm.DB, postID uint, delta int) error {
+	commentsCount := gorm.Expr("comments_count + ?", delta)
+	if delta < 0 {
+		commentsCount = gorm.Expr("GREATEST(comments_count, ?) - ?", -delta, -delta)
+	}
 
-	return
+	return tx.Model(&Post{}).Where("id = ?", postID).UpdateColumns(map[string]interface{}{
+		"comments_count": commentsCount,
+		"commented_at":   gorm.Expr("COALESCE((SELECT MAX(created_at) FROM comments WHERE post_id = ? AND deleted_at IS NULL), ?)", postID, time.Unix(0, 0)),
+	}).Error
 }
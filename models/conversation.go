@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Private conversation between two Users, or a small group of them
+type Conversation struct {
+	BaseModel
+
+	// Only group Conversations have a title, one-to-one Conversations are named after the other User
+	Title   string
+	IsGroup bool `gorm:"default:false"`
+
+	// "smallerUserID:largerUserID" for one-to-one Conversations so that there is only one per pair of Users.
+	// Nil for group Conversations and for one-to-one Conversations that someone has left.
+	DirectKey *string `gorm:"size:64;uniqueIndex"`
+
+	LastMessageAt time.Time `gorm:"autoCreateTime;index"`
+
+	Participants []Participant `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// User taking part in a Conversation. Leaving a Conversation deletes the Participant.
+type Participant struct {
+	BaseModel
+
+	ConversationID uint `gorm:"uniqueIndex:idx_participant_conversation_user"`
+
+	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID uint `gorm:"uniqueIndex:idx_participant_conversation_user;index"`
+
+	// Messages up to (and including) this ID have been read by the User
+	LastReadMessageID uint `gorm:"default:0"`
+}
+
+type Message struct {
+	BaseModel
+
+	Conversation   Conversation `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ConversationID uint         `gorm:"index"`
+
+	// Sender
+	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID uint
+
+	Text     string
+	TextHTML string
+}
@@ -0,0 +1,13 @@
+package models
+
+type Follow struct {
+	BaseModel
+
+	// User that follows
+	Follower   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	FollowerID uint `gorm:"uniqueIndex:idx_follow_follower_user"`
+
+	// User being followed
+	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID uint `gorm:"uniqueIndex:idx_follow_follower_user;index"`
+}
@@ -0,0 +1,16 @@
+package models
+
+type Mention struct {
+	BaseModel
+
+	// Mentioned User
+	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID uint `gorm:"index"`
+
+	Post   Post `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PostID uint `gorm:"index"`
+
+	// Set if the mention is in a Comment rather than in the Post itself
+	Comment   *Comment `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CommentID *uint    `gorm:"index"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// Content of muted Users is left out of the listings of the User that muted them.
+// Unlike a Block, the muted User is not affected.
+type Mute struct {
+	BaseModel
+
+	// User that mutes
+	Muter   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	MuterID uint `gorm:"uniqueIndex:idx_mute_muter_user"`
+
+	// User being muted
+	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID uint `gorm:"uniqueIndex:idx_mute_muter_user;index"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+type Notification struct {
+	BaseModel
+
+	// Recipient
+	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID uint `gorm:"index"`
+
+	// User that caused the Notification (hidden if Anonymous)
+	Actor     User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ActorID   uint
+	Anonymous bool `gorm:"default:false"`
+
+	// See config NOTIFICATION TYPES
+	Type string
+
+	PostID    uint
+	CommentID *uint
+
+	ReadAt *time.Time
+}
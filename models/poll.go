@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+type Poll struct {
+	BaseModel
+
+	PostID uint `gorm:"uniqueIndex"`
+
+	MultipleChoice bool `gorm:"default:false"`
+	// Results are only shown once the Poll has closed
+	HideResults bool `gorm:"default:false"`
+	ClosesAt    *time.Time
+
+	VotersCount uint `gorm:"default:0"`
+
+	Options []PollOption `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (poll *Poll) IsClosed() bool {
+	return poll.ClosesAt != nil && time.Now().After(*poll.ClosesAt)
+}
+
+type PollOption struct {
+	BaseModel
+
+	PollID   uint
+	Text     string
+	Position uint
+
+	VotesCount uint `gorm:"default:0"`
+}
+
+type PollVote struct {
+	BaseModel
+
+	Poll   Poll `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PollID uint `gorm:"index"`
+
+	PollOption   PollOption `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PollOptionID uint       `gorm:"uniqueIndex:idx_poll_option_user"`
+
+	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID uint `gorm:"uniqueIndex:idx_poll_option_user"`
+}
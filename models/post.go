@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 var ValidTags = [4]string{"general", "cs", "life", "misc"}
@@ -9,15 +11,41 @@ var ValidTags = [4]string{"general", "cs", "life", "misc"}
 type Post struct {
 	BaseModel
 
-	Title string
-	Tag   string
-	Text  string
+	Title    string
+	Tag      string
+	Text     string
+	TextHTML string
+
+	// Drafts and scheduled Posts are only visible to their author
+	Status    string `gorm:"default:published;index"`
+	PublishAt *time.Time
 
 	Author string
 	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	UserID uint
 
+	// Author and UserID are only revealed to the author and moderators
+	Anonymous bool `gorm:"default:false"`
+
 	CommentsCount uint
 	CommentedAt   time.Time
 	StarsCount    uint
+	ViewsCount    uint `gorm:"default:0"`
+
+	// Comment accepted as the answer (only for config.QA_TAGS)
+	AcceptedCommentID *uint
+
+	Attachments []Attachment `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Poll        *Poll        `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
+	Locked       bool `gorm:"default:false"`
+	LockedReason string
+	LockedByID   uint
+
+	// Hidden from other Users after config.REPORTS_TO_HIDE reports (until dismissed)
+	Hidden bool `gorm:"default:false"`
+
+	// Soft deleted Posts can be restored until they are purged
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	DeletedByID uint
 }
@@ -0,0 +1,14 @@
+package models
+
+type Reaction struct {
+	BaseModel
+
+	Comment   Comment `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CommentID uint    `gorm:"uniqueIndex:idx_reaction_comment_user"`
+
+	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID uint `gorm:"uniqueIndex:idx_reaction_comment_user"`
+
+	// See config REACTION TYPES
+	Type string `gorm:"size:16"`
+}
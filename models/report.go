@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+type Report struct {
+	BaseModel
+
+	Reporter   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ReporterID uint `gorm:"index:idx_report_reporter"`
+
+	// Reported Post, Comment or User (see config REPORT TARGETS)
+	TargetType   string `gorm:"size:16;index:idx_report_target"`
+	TargetID     uint   `gorm:"index:idx_report_target"`
+	TargetUserID uint
+
+	Reason  string
+	Details string
+
+	Status       string `gorm:"default:pending;index"`
+	Outcome      string
+	Note         string
+	ResolvedByID uint
+	ResolvedAt   *time.Time
+}
@@ -0,0 +1,11 @@
+package models
+
+type Star struct {
+	BaseModel
+
+	Post   Post `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PostID uint `gorm:"uniqueIndex:idx_star_post_user"`
+
+	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID uint `gorm:"uniqueIndex:idx_star_post_user"`
+}
@@ -0,0 +1,14 @@
+package models
+
+type Subscription struct {
+	BaseModel
+
+	Post   Post `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PostID uint `gorm:"uniqueIndex:idx_subscription_post_user"`
+
+	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID uint `gorm:"uniqueIndex:idx_subscription_post_user"`
+
+	// Unsubscribing keeps the row so that Users are not auto-subscribed again
+	Subscribed bool
+}
@@ -0,0 +1,9 @@
+package models
+
+type TagFollow struct {
+	BaseModel
+
+	User   User   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID uint   `gorm:"uniqueIndex:idx_tag_follow_user_tag"`
+	Tag    string `gorm:"size:64;uniqueIndex:idx_tag_follow_user_tag"`
+}
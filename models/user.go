@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type User struct {
@@ -13,9 +15,41 @@ type User struct {
 	Bio      string
 	Private  bool `gorm:"default:false"`
 
+	// Optional profile details, empty (or 0) when not set
+	DisplayName string `gorm:"size:64"`
+	Pronouns    string `gorm:"size:32"`
+	Faculty     string
+	Major       string
+	Year        uint `gorm:"default:0"`
+
+	// Resized avatar image in storage (if any)
+	AvatarKey string
+
+	Links []ProfileLink `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
 	PostsCount    uint `gorm:"default:0"`
 	CommentsCount uint `gorm:"default:0"`
 
+	FollowersCount uint `gorm:"default:0"`
+	FollowingCount uint `gorm:"default:0"`
+
 	LastPostAt    time.Time `gorm:"autoCreateTime"`
 	LastCommentAt time.Time `gorm:"autoCreateTime"`
+	LastMessageAt time.Time `gorm:"autoCreateTime"`
+
+	// Read-only token for RSS and Atom feeds (feed readers cannot send a JWT)
+	FeedToken *string `gorm:"size:64;uniqueIndex"`
+
+	// Soft deleted Users can be restored by admins until they are purged
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// Link (e.g. GitHub, LinkedIn, personal website) shown on a User's profile
+type ProfileLink struct {
+	BaseModel
+
+	UserID   uint `gorm:"index"`
+	Label    string
+	URL      string
+	Position uint
 }
@@ -0,0 +1,13 @@
+package models
+
+// Previous username of a User, kept so that old profile links can be redirected
+// and the old username is not taken by someone else right away
+type UsernameChange struct {
+	BaseModel
+
+	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID uint `gorm:"index"`
+
+	OldUsername string `gorm:"size:191;index"`
+	NewUsername string `gorm:"size:191"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// A (deduplicated) view of a Post by a User. CreatedAt is the time of the view.
+type PostView struct {
+	BaseModel
+
+	Post   Post `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PostID uint `gorm:"index"`
+
+	User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID uint
+}
@@ -2,8 +2,15 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/mfjkri/OneNUS-Backend/controllers/admin"
+	"github.com/mfjkri/OneNUS-Backend/controllers/attachments"
 	"github.com/mfjkri/OneNUS-Backend/controllers/comments"
+	"github.com/mfjkri/OneNUS-Backend/controllers/feeds"
+	"github.com/mfjkri/OneNUS-Backend/controllers/messages"
+	"github.com/mfjkri/OneNUS-Backend/controllers/notifications"
+	"github.com/mfjkri/OneNUS-Backend/controllers/polls"
 	"github.com/mfjkri/OneNUS-Backend/controllers/posts"
+	"github.com/mfjkri/OneNUS-Backend/controllers/reports"
 	"github.com/mfjkri/OneNUS-Backend/controllers/users"
 )
 
@@ -11,4 +18,11 @@ func RegisterProtectedRoutes(r *gin.Engine) {
 	posts.RegisterRoutes(r)
 	comments.RegisterRoutes(r)
 	users.RegisterRoutes(r)
+	attachments.RegisterRoutes(r)
+	polls.RegisterRoutes(r)
+	reports.RegisterRoutes(r)
+	notifications.RegisterRoutes(r)
+	feeds.RegisterRoutes(r)
+	messages.RegisterRoutes(r)
+	admin.RegisterRoutes(r)
 }
@@ -4,13 +4,21 @@ import (
 	"os"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mfjkri/OneNUS-Backend/controllers/attachments"
 	"github.com/mfjkri/OneNUS-Backend/controllers/auth"
+	"github.com/mfjkri/OneNUS-Backend/controllers/feeds"
 )
 
 func RegisterPublicRoutes(r *gin.Engine) {
 	// auth.go
 	auth.RegisterRoutes(r)
 
+	// attachments (signed file URLs)
+	attachments.RegisterPublicRoutes(r)
+
+	// feeds (authenticated with feed tokens)
+	feeds.RegisterPublicRoutes(r)
+
 	// misc
 	r.GET("ping", func(c *gin.Context) {
 		c.JSON(200, gin.H{
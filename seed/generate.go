@@ -13,6 +13,7 @@ import (
 	"github.com/mfjkri/OneNUS-Backend/config"
 	"github.com/mfjkri/OneNUS-Backend/database"
 	"github.com/mfjkri/OneNUS-Backend/models"
+	"github.com/mfjkri/OneNUS-Backend/utils"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -81,7 +82,7 @@ func ChooseRandomTag() string {
 }
 
 func GeneratePost(user models.User) models.Post {
-	return models.Post{
+	post := models.Post{
 		Title: faker.Sentence(options.WithRandomStringLength(uint(config.MAX_POST_TITLE_CHAR))),
 		Tag:   ChooseRandomTag(),
 		Text:  faker.Paragraph(options.WithRandomStringLength(uint(config.MAX_POST_TEXT_CHAR))),
@@ -93,6 +94,8 @@ func GeneratePost(user models.User) models.Post {
 		CommentedAt:   time.Unix(0, 0),
 		StarsCount:    uint(rand.Intn((100))),
 	}
+	post.TextHTML = utils.RenderMarkdown(post.Text, config.CODE_HIGHLIGHT_TAGS[post.Tag])
+	return post
 }
 
 func GeneratePosts(number int, user models.User, creationTime time.Time) {
@@ -127,7 +130,7 @@ func GeneratePostsForEachUser(maxPostPerUser int, initialTime time.Time) {
 /*                              Generate Comments                             */
 /* -------------------------------------------------------------------------- */
 func GenerateComment(user models.User, post models.Post) models.Comment {
-	return models.Comment{
+	comment := models.Comment{
 		Text: faker.Paragraph(options.WithRandomStringLength(uint(config.MAX_COMMENT_TEXT_CHAR))),
 
 		Author: user.Username,
@@ -135,6 +138,8 @@ func GenerateComment(user models.User, post models.Post) models.Comment {
 
 		Post: post,
 	}
+	comment.TextHTML = utils.RenderMarkdown(comment.Text, config.CODE_HIGHLIGHT_TAGS[post.Tag])
+	return comment
 }
 
 func GenerateComments(number int, user models.User, post models.Post) {
@@ -21,8 +21,93 @@ func DeleteComments() {
 	database.DB.Migrator().DropTable("comments")
 }
 
+func DeleteProfileLinks() {
+	fmt.Println("Deleting profile links")
+	database.DB.Migrator().DropTable("profile_links")
+}
+
+func DeleteUsernameChanges() {
+	fmt.Println("Deleting username changes")
+	database.DB.Migrator().DropTable("username_changes")
+}
+
+func DeleteBlocks() {
+	fmt.Println("Deleting blocks and mutes")
+	database.DB.Migrator().DropTable("blocks", "mutes")
+}
+
+func DeleteConversations() {
+	fmt.Println("Deleting conversations")
+	database.DB.Migrator().DropTable("messages", "participants", "conversations")
+}
+
+func DeleteAttachments() {
+	fmt.Println("Deleting attachments")
+	database.DB.Migrator().DropTable("attachments")
+}
+
+func DeletePolls() {
+	fmt.Println("Deleting polls")
+	database.DB.Migrator().DropTable("poll_votes", "poll_options", "polls")
+}
+
+func DeletePostViews() {
+	fmt.Println("Deleting post views")
+	database.DB.Migrator().DropTable("post_views")
+}
+
+func DeleteStars() {
+	fmt.Println("Deleting stars")
+	database.DB.Migrator().DropTable("stars")
+}
+
+func DeleteReports() {
+	fmt.Println("Deleting reports")
+	database.DB.Migrator().DropTable("reports")
+}
+
+func DeleteMentions() {
+	fmt.Println("Deleting mentions")
+	database.DB.Migrator().DropTable("mentions")
+}
+
+func DeleteNotifications() {
+	fmt.Println("Deleting notifications")
+	database.DB.Migrator().DropTable("notifications")
+}
+
+func DeleteFollows() {
+	fmt.Println("Deleting follows")
+	database.DB.Migrator().DropTable("follows")
+	database.DB.Migrator().DropTable("tag_follows")
+}
+
+func DeleteSubscriptions() {
+	fmt.Println("Deleting subscriptions")
+	database.DB.Migrator().DropTable("subscriptions")
+}
+
+func DeleteReactions() {
+	fmt.Println("Deleting reactions")
+	database.DB.Migrator().DropTable("reactions")
+}
+
 func DeleteAll() {
 	fmt.Println("RESETTING DATABASE")
+	DeletePostViews()
+	DeleteStars()
+	DeleteReports()
+	DeleteMentions()
+	DeleteNotifications()
+	DeleteFollows()
+	DeleteBlocks()
+	DeleteConversations()
+	DeleteSubscriptions()
+	DeleteReactions()
+	DeleteAttachments()
+	DeletePolls()
+	DeleteProfileLinks()
+	DeleteUsernameChanges()
 	DeleteUsers()
 	DeletePosts()
 	DeleteComments()
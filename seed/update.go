@@ -2,56 +2,29 @@ package seed
 
 import (
 	"fmt"
-	"time"
 
+	"github.com/mfjkri/OneNUS-Backend/config"
 	"github.com/mfjkri/OneNUS-Backend/database"
 	"github.com/mfjkri/OneNUS-Backend/models"
+	"github.com/mfjkri/OneNUS-Backend/utils"
 )
 
-func UpdateUsers() {
-	fmt.Println("Updating users data...")
-	var users []models.User
-	database.DB.Find(&users)
-
-	for _, user := range users {
-		var totalPostsCount int64
-		database.DB.Table("posts").Where("user_id = ?", user.ID).Count(&totalPostsCount)
-		user.PostsCount = uint(totalPostsCount)
-
-		var totalCommentsCount int64
-		database.DB.Table("comments").Where("user_id = ?", user.ID).Count(&totalCommentsCount)
-		user.CommentsCount = uint(totalCommentsCount)
-
-		database.DB.Save(&user)
-	}
-	fmt.Println("Update users data complete!")
-}
-
-func UpdatePosts() {
-	fmt.Println("Updating posts data...")
+func RenderTexts() {
+	fmt.Println("Rendering posts and comments text...")
 
 	var posts []models.Post
-	database.DB.Find(&posts)
-
+	database.DB.Where("text_html = ?", "").Find(&posts)
 	for _, post := range posts {
-		dbContext := database.DB.Table("comments").Where("post_id = ?", post.ID)
-
-		var totalCommentsCount int64
-		dbContext.Count(&totalCommentsCount)
-		post.CommentsCount = uint(totalCommentsCount)
-
-		var lastComment models.Comment
-		dbContext.Order("created_at DESC, id DESC").First(&lastComment)
-		if lastComment.ID != 0 {
-			post.CommentedAt = lastComment.CreatedAt
-		} else {
-			post.CommentedAt = time.Unix(0, 0)
-		}
+		textHTML := utils.RenderMarkdown(post.Text, config.CODE_HIGHLIGHT_TAGS[post.Tag])
+		database.DB.Model(&post).UpdateColumn("text_html", textHTML)
+	}
 
-		postUpdatedAt := post.UpdatedAt
-		database.DB.Save(&post)
-		database.DB.Model(&post).Update("updated_at", postUpdatedAt)
+	var comments []models.Comment
+	database.DB.Preload("Post").Where("text_html = ?", "").Find(&comments)
+	for _, comment := range comments {
+		textHTML := utils.RenderMarkdown(comment.Text, config.CODE_HIGHLIGHT_TAGS[comment.Post.Tag])
+		database.DB.Model(&comment).UpdateColumn("text_html", textHTML)
 	}
 
-	fmt.Println("Update posts data complete!")
+	fmt.Println("Render posts and comments text complete!")
 }
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Only used outside of release mode when STORAGE_SIGNING_SECRET is not set
+const devSigningSecret = "onenus-insecure-development-secret"
+
+// Stores objects on the local disk.
+// Objects are served by the API itself at BaseURL/storage/<key> using HMAC signed URLs.
+type LocalStorage struct {
+	Dir     string
+	BaseURL string
+	Secret  []byte
+}
+
+func NewLocalStorage(dir string, baseURL string, secret string) (*LocalStorage, error) {
+	if dir == "" {
+		dir = "uploads"
+	}
+	// Outside of release mode an unset secret falls back to a well-known one so existing setups keep working
+	if secret == "" {
+		if os.Getenv("GIN_MODE") == "release" {
+			return nil, errors.New("STORAGE_SIGNING_SECRET must be set in release mode (see docs/storage.md)")
+		}
+		fmt.Println("WARNING: STORAGE_SIGNING_SECRET is not set, signing file URLs with an insecure development secret...")
+		secret = devSigningSecret
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &LocalStorage{
+		Dir:     dir,
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Secret:  []byte(secret),
+	}, nil
+}
+
+// Resolves key to a path inside Dir, refusing keys that escape it
+func (s *LocalStorage) Path(key string) (string, error) {
+	path := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, filepath.Clean(s.Dir)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("invalid storage key: %s", key)
+	}
+	return path, nil
+}
+
+func (s *LocalStorage) Put(key string, reader io.Reader, size int64, contentType string) error {
+	path, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, reader)
+	return err
+}
+
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) sign(key string, expires string) string {
+	mac := hmac.New(sha256.New, s.Secret)
+	mac.Write([]byte(key + ":" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalStorage) SignedURL(key string, expiry time.Duration) (string, error) {
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(key, expires))
+
+	return fmt.Sprintf("%s/storage/%s?%s", s.BaseURL, key, query.Encode()), nil
+}
+
+// Checks that a signed URL for key was issued by us and has not expired
+func (s *LocalStorage) VerifySignature(key string, expires string, signature string) bool {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte(s.sign(key, expires)))
+}
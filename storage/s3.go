@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// Stores objects in an S3-compatible bucket (AWS S3, MinIO, ...).
+// Objects are served directly from the bucket using presigned URLs.
+type S3Storage struct {
+	Client *minio.Client
+	Bucket string
+}
+
+func NewS3Storage(endpoint string, accessKey string, secretKey string, bucket string, useSSL bool) (*S3Storage, error) {
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: useSSL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the bucket on first use (e.g. fresh local MinIO)
+	ctx := context.Background()
+	exists, err := client.BucketExists(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &S3Storage{
+		Client: client,
+		Bucket: bucket,
+	}, nil
+}
+
+func (s *S3Storage) Put(key string, reader io.Reader, size int64, contentType string) error {
+	_, err := s.Client.PutObject(context.Background(), s.Bucket, key, reader, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+func (s *S3Storage) Delete(key string) error {
+	return s.Client.RemoveObject(context.Background(), s.Bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *S3Storage) SignedURL(key string, expiry time.Duration) (string, error) {
+	signedURL, err := s.Client.PresignedGetObject(context.Background(), s.Bucket, key, expiry, nil)
+	if err != nil {
+		return "", err
+	}
+	return signedURL.String(), nil
+}
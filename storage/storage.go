@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Backend agnostic object storage used for user uploaded files
+type Storage interface {
+	// Saves the contents of reader under key, replacing any existing object
+	Put(key string, reader io.Reader, size int64, contentType string) error
+
+	// Removes the object saved under key (if any)
+	Delete(key string) error
+
+	// Returns a URL that grants read access to key until expiry has passed
+	SignedURL(key string, expiry time.Duration) (string, error)
+}
+
+var Store Storage
+
+func Connect() {
+	var err error
+
+	switch os.Getenv("STORAGE_BACKEND") {
+	case "s3":
+		Store, err = NewS3Storage(
+			os.Getenv("S3_ENDPOINT"),
+			os.Getenv("S3_ACCESS_KEY"),
+			os.Getenv("S3_SECRET_KEY"),
+			os.Getenv("S3_BUCKET"),
+			os.Getenv("S3_USE_SSL") == "true",
+		)
+	default:
+		Store, err = NewLocalStorage(
+			os.Getenv("STORAGE_LOCAL_DIR"),
+			os.Getenv("STORAGE_PUBLIC_URL"),
+			os.Getenv("STORAGE_SIGNING_SECRET"),
+		)
+	}
+
+	if err != nil {
+		panic(fmt.Sprintf("Failed to connect to storage: %s", err))
+	}
+
+	fmt.Println("Successfully connected to storage...")
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"os"
+)
+
+var pseudonymAdjectives = [32]string{
+	"Amber", "Bold", "Brave", "Calm", "Clever", "Cosmic", "Crimson", "Curious",
+	"Daring", "Eager", "Fuzzy", "Gentle", "Golden", "Happy", "Humble", "Jolly",
+	"Lucky", "Mellow", "Misty", "Nimble", "Quiet", "Rapid", "Rusty", "Shy",
+	"Silver", "Sleepy", "Sunny", "Swift", "Tidy", "Velvet", "Witty", "Zesty",
+}
+
+var pseudonymAnimals = [32]string{
+	"Badger", "Bear", "Beaver", "Bison", "Cat", "Crane", "Deer", "Dolphin",
+	"Eagle", "Falcon", "Ferret", "Fox", "Gecko", "Hare", "Hedgehog", "Heron",
+	"Koala", "Lemur", "Lynx", "Macaque", "Otter", "Owl", "Panda", "Pangolin",
+	"Penguin", "Puffin", "Raccoon", "Seal", "Sloth", "Tapir", "Tiger", "Turtle",
+}
+
+// Name shown in place of a User's username for anonymous content.
+// It is stable for a User within a thread (postID) but differs across threads.
+func Pseudonym(postID uint, userID uint) string {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("ANONYMOUS_SECRET")))
+	mac.Write([]byte(fmt.Sprintf("%d:%d", postID, userID)))
+	sum := mac.Sum(nil)
+
+	adjective := pseudonymAdjectives[sum[0]%32]
+	animal := pseudonymAnimals[sum[1]%32]
+	number := binary.BigEndian.Uint16(sum[2:4]) % 100
+
+	return fmt.Sprintf("Anonymous %s %s %02d", adjective, animal, number)
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Reads the dimensions and format of an encoded image without decoding all of it
+func DecodeImageConfig(data []byte) (image.Config, string, error) {
+	return image.DecodeConfig(bytes.NewReader(data))
+}
+
+// Scales an encoded image down to fit within maxSize x maxSize and re-encodes it.
+// PNGs keep their format (and transparency), everything else is encoded as JPEG.
+// Returns the encoded image and its content type.
+func ResizeImage(data []byte, maxSize int) ([]byte, string, error) {
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Keep aspect ratio, never scale up
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxSize || height > maxSize {
+		if width > height {
+			height = height * maxSize / width
+			width = maxSize
+		} else {
+			width = width * maxSize / height
+			height = maxSize
+		}
+	}
+
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	var buf bytes.Buffer
+	if format == "png" {
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+		err = png.Encode(&buf, dst)
+		return buf.Bytes(), "image/png", err
+	}
+
+	// JPEGs have no transparency so flatten onto a white background
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+	err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+	return buf.Bytes(), "image/jpeg", err
+}
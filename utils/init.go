@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/joho/godotenv"
 )
@@ -11,5 +12,10 @@ func LoadEnv() {
 		panic("Failed to load .env file!")
 	}
 
+	// Pseudonyms are only as private as this secret, refuse to run without one
+	if os.Getenv("ANONYMOUS_SECRET") == "" {
+		panic("ANONYMOUS_SECRET is not set!")
+	}
+
 	fmt.Println("Successfully import .env variables...")
 }
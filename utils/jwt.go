@@ -3,23 +3,31 @@ package utils
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
-func GenerateJWT(username string) (tokenString string, err error) {
+// JWT token valid for 1 week
+// TODO Set to 15 minutes and add refreshToken logic
+const jwtValidity = time.Hour * 168
+
+// The subject of the token is the user ID so that it stays valid when the user changes their username
+func GenerateJWT(userID uint) (tokenString string, err error) {
+	now := time.Now()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": username,
-		// JWT token valid for 1 week
-		// TODO Set to 15 minutes and add refreshToken logic
-		"exp": time.Now().Add(time.Hour * 168).Unix(),
+		"sub": strconv.FormatUint(uint64(userID), 10),
+		"iat": now.Unix(),
+		"exp": now.Add(jwtValidity).Unix(),
 	})
 
 	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
 }
 
-func DecodeJWT(tokenString string) (username string, err error) {
+// Returns the subject of a valid token and when it was issued.
+// Tokens issued before subjects were user IDs have the username as subject (and no iat).
+func DecodeJWT(tokenString string) (subject string, issuedAt time.Time, err error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		// Don't forget to validate the alg is what you expect:
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -30,18 +38,23 @@ func DecodeJWT(tokenString string) (username string, err error) {
 	})
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		username = claims["sub"].(string)
+		subject, _ = claims["sub"].(string)
+		if iat, found := claims["iat"].(float64); found {
+			issuedAt = time.Unix(int64(iat), 0)
+		} else if exp, found := claims["exp"].(float64); found {
+			issuedAt = time.Unix(int64(exp), 0).Add(-jwtValidity)
+		}
 		return
 	}
 
-	username = ""
+	subject = ""
 	return
 }
 
-func ValidateJWT(tokenString string, username string) (jwtValid bool, err error) {
-	decoded_username, err := DecodeJWT(tokenString)
+func ValidateJWT(tokenString string, userID uint) (jwtValid bool, err error) {
+	subject, _, err := DecodeJWT(tokenString)
 
-	if decoded_username == username {
+	if subject == strconv.FormatUint(uint64(userID), 10) {
 		jwtValid = true
 		return
 	}
@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                  Mentions                                  */
+/* -------------------------------------------------------------------------- */
+// Usernames (lowercased) found by ExtractMentions are collected here
+var mentionCandidatesKey = parser.NewContextKey()
+
+// Resolved mentions (lowercased username to UserID) that are rendered as links
+var resolvedMentionsKey = parser.NewContextKey()
+
+// Parses @username outside of code. Usernames are letters only (see auth.RegisterUser).
+type mentionParser struct{}
+
+func (p *mentionParser) Trigger() []byte {
+	return []byte{'@'}
+}
+
+func (p *mentionParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	// Mentions cannot follow a letter or digit (e.g. email addresses)
+	precedingCharacter := block.PrecendingCharacter()
+	if unicode.IsLetter(precedingCharacter) || unicode.IsDigit(precedingCharacter) {
+		return nil
+	}
+
+	line, segment := block.PeekLine()
+	nameLength := 0
+	for nameLength+1 < len(line) {
+		r, size := utf8.DecodeRune(line[1+nameLength:])
+		if !unicode.IsLetter(r) {
+			if unicode.IsDigit(r) || r == '_' {
+				return nil
+			}
+			break
+		}
+		nameLength += size
+	}
+	if nameLength == 0 {
+		return nil
+	}
+	username := strings.ToLower(string(line[1 : 1+nameLength]))
+
+	// Collecting mentions only, leave the text as is
+	if candidates, ok := pc.Get(mentionCandidatesKey).(*[]string); ok {
+		*candidates = append(*candidates, username)
+		return nil
+	}
+
+	mentions, _ := pc.Get(resolvedMentionsKey).(map[string]uint)
+	userID, found := mentions[username]
+	if found == false {
+		return nil
+	}
+
+	block.Advance(1 + nameLength)
+	link := ast.NewLink()
+	link.Destination = []byte(fmt.Sprintf("/users/%d", userID))
+	link.SetAttributeString("class", []byte("mention"))
+	link.AppendChild(link, ast.NewTextSegment(segment.WithStop(segment.Start+1+nameLength)))
+	return link
+}
+
+var mentionParserOption = goldmark.WithParserOptions(
+	parser.WithInlineParsers(util.Prioritized(&mentionParser{}, 999)),
+)
+
+// Returns the unique (lowercased) usernames mentioned in text, in order of appearance.
+// Mentions inside code are ignored.
+func ExtractMentions(source string) []string {
+	var candidates []string
+	context := parser.NewContext()
+	context.Set(mentionCandidatesKey, &candidates)
+	markdownRenderer.Parser().Parse(text.NewReader([]byte(source)), parser.WithContext(context))
+
+	var usernames []string
+	seen := map[string]bool{}
+	for _, username := range candidates {
+		if !seen[username] {
+			seen[username] = true
+			usernames = append(usernames, username)
+		}
+	}
+	return usernames
+}
+
+/* -------------------------------------------------------------------------- */
+/*                                  Rendering                                 */
+/* -------------------------------------------------------------------------- */
+// CommonMark renderer. Raw HTML in the source is never rendered.
+var markdownRenderer = goldmark.New(mentionParserOption)
+
+// CommonMark renderer that also syntax highlights fenced code blocks.
+// Highlighting is emitted as CSS classes so that no inline styles are needed.
+var highlightedMarkdownRenderer = goldmark.New(
+	mentionParserOption,
+	goldmark.WithExtensions(
+		highlighting.NewHighlighting(
+			highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+		),
+	),
+)
+
+// Sanitizer applied to all rendered HTML before it is stored
+var markdownPolicy = func() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^[a-zA-Z0-9\-_ ]+$`)).OnElements("pre", "code", "span")
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^mention$`)).OnElements("a")
+	policy.RequireNoFollowOnLinks(true)
+	return policy
+}()
+
+// Renders CommonMark text into sanitized HTML.
+// Fenced code blocks are syntax highlighted if highlightCode is set.
+func RenderMarkdown(text string, highlightCode bool) string {
+	return RenderMarkdownWithMentions(text, highlightCode, nil)
+}
+
+// Same as RenderMarkdown but also renders mentions (lowercased username to UserID) as links
+func RenderMarkdownWithMentions(text string, highlightCode bool, mentions map[string]uint) string {
+	renderer := markdownRenderer
+	if highlightCode {
+		renderer = highlightedMarkdownRenderer
+	}
+
+	context := parser.NewContext()
+	context.Set(resolvedMentionsKey, mentions)
+
+	var buf bytes.Buffer
+	if err := renderer.Convert([]byte(text), &buf, parser.WithContext(context)); err != nil {
+		return "<p>" + html.EscapeString(text) + "</p>"
+	}
+
+	return markdownPolicy.Sanitize(buf.String())
+}
@@ -0,0 +1,15 @@
+package utils
+
+import "time"
+
+// Runs job every interval on a separate goroutine
+func RunEvery(interval time.Duration, job func()) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			job()
+		}
+	}()
+}
@@ -0,0 +1,16 @@
+package utils
+
+import "math"
+
+// Lower bound of the Wilson score confidence interval (95%) for the share of upvotes.
+// Ranks items with few votes below items with many votes of the same ratio.
+func WilsonLowerBound(upvotes uint, downvotes uint) float64 {
+	n := float64(upvotes + downvotes)
+	if n == 0 {
+		return 0
+	}
+
+	const z = 1.96
+	phat := float64(upvotes) / n
+	return (phat + z*z/(2*n) - z*math.Sqrt((phat*(1-phat)+z*z/(4*n))/n)) / (1 + z*z/n)
+}
@@ -1,10 +1,13 @@
 package utils
 
 import (
+	"fmt"
 	"regexp"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 
-	"golang.org/x/exp/utf8string"
+	"golang.org/x/text/unicode/norm"
 )
 
 func ContainsNumbers(s string) bool {
@@ -28,14 +31,84 @@ func ContainsLettersOnly(s string) bool {
 	return true
 }
 
-func ContainsValidCharactersOnly(s string) bool {
-	return utf8string.NewString(s).IsASCII()
+// Number of characters (not bytes) in s
+func CharacterCount(s string) int {
+	return utf8.RuneCountInString(s)
 }
 
-func TrimString(s string, maxLen int) string {
-	if len(s) >= maxLen {
-		return s[:maxLen]
+// Bidi marks, embeddings, overrides and isolates can be used to visually reorder text
+func isBidiOverride(r rune) bool {
+	return r == '\u200E' || r == '\u200F' || r == '\u061C' || (r >= '\u202A' && r <= '\u202E') || (r >= '\u2066' && r <= '\u2069')
+}
+
+// Invisible characters that have no legitimate use in user text
+func isZeroWidth(r rune) bool {
+	return r == '\u200B' || r == '\u2060' || r == '\uFEFF' || r == '\u180E' || r == '\u00AD'
+}
+
+// Zero-width (non-)joiners are needed by emoji sequences and several scripts,
+// so they are only allowed in between two visible characters
+func isJoiner(r rune) bool {
+	return r == '\u200C' || r == '\u200D'
+}
+
+func isVisible(r rune) bool {
+	return !(unicode.IsSpace(r) || unicode.IsControl(r) || isBidiOverride(r) || isZeroWidth(r) || isJoiner(r))
+}
+
+// Checks s for control, bidi-override and zero-width characters.
+// Newlines and tabs are only allowed if multiline is set.
+func ContainsIllegalCharacters(s string, multiline bool) bool {
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r == '\n' || r == '\t':
+			if !multiline {
+				return true
+			}
+		case unicode.IsControl(r), isBidiOverride(r), isZeroWidth(r):
+			return true
+		case isJoiner(r):
+			if i == 0 || i == len(runes)-1 || !isVisible(runes[i-1]) || !isVisible(runes[i+1]) {
+				return true
+			}
+		}
 	}
+	return false
+}
+
+// Normalizes user text to NFC with consistent newlines and no surrounding whitespace
+func NormalizeText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return strings.TrimSpace(norm.NFC.String(s))
+}
+
+func validateText(field string, s string, maxLen int, multiline bool) (string, error) {
+	s = NormalizeText(s)
+
+	if s == "" {
+		return s, fmt.Errorf("%s cannot be empty.", field)
+	}
+
+	if ContainsIllegalCharacters(s, multiline) {
+		return s, fmt.Errorf("%s contains illegal characters.", field)
+	}
+
+	if CharacterCount(s) > maxLen {
+		return s, fmt.Errorf("%s is too long (max %d characters).", field, maxLen)
+	}
+
+	return s, nil
+}
+
+// Normalizes and validates multi-line user text (e.g. post body) of at most maxLen characters.
+// field is used to name the text in the returned error.
+func ValidateText(field string, s string, maxLen int) (string, error) {
+	return validateText(field, s, maxLen, true)
+}
 
-	return s
+// Same as ValidateText but for single-line user text (e.g. post title)
+func ValidateLine(field string, s string, maxLen int) (string, error) {
+	return validateText(field, s, maxLen, false)
 }